@@ -0,0 +1,149 @@
+package client
+
+import (
+	"context"
+	"sync"
+
+	"github.com/timpalpant/yahtzee"
+	"github.com/timpalpant/yahtzee/optimization"
+	"github.com/timpalpant/yahtzee/server"
+)
+
+// Advisor gives advice on how to play a game of Yahtzee. It is
+// implemented by Client, which asks a Yahtzee server, and by Local,
+// which uses strategy tables loaded into the current process.
+type Advisor interface {
+	// GetOptimalMove returns the best move for the given roll. If
+	// scoreToBeat (the score needed over the remaining turns) is
+	// provided, currentScore is used to decide whether it would be
+	// better to start a new game.
+	GetOptimalMove(ctx context.Context, game yahtzee.GameState, step yahtzee.TurnStep,
+		roll []int, scoreToBeat, currentScore int) (*server.OptimalMoveResponse, error)
+	// GetGameValue returns the value of the game at the beginning of a
+	// turn: the expected remaining score if scoreToBeat is 0, and the
+	// negative expected work to achieve scoreToBeat otherwise.
+	GetGameValue(ctx context.Context, game yahtzee.GameState, scoreToBeat int) (float32, error)
+	// OptimalMove returns the best move for the position in req.
+	OptimalMove(ctx context.Context, req *server.OptimalMoveRequest) (*server.OptimalMoveResponse, error)
+	// OutcomeDistribution returns the distribution of final scores
+	// for every choice in the position in req.
+	OutcomeDistribution(ctx context.Context,
+		req *server.OutcomeDistributionRequest) (*server.OutcomeDistributionResponse, error)
+	// Explain ranks and explains every choice in the position in req.
+	Explain(ctx context.Context, req *server.ExplainRequest) (*server.ExplainResponse, error)
+}
+
+var (
+	_ Advisor = (*Client)(nil)
+	_ Advisor = (*Local)(nil)
+)
+
+// Local is an Advisor that computes advice in the current process,
+// without a Yahtzee server. It returns the same results and errors
+// as a Client of a server with the same tables.
+type Local struct {
+	ys *server.YahtzeeServer
+}
+
+// NewLocal loads the strategy tables from the given files, keyed by
+// table name (see server.TableNames). Tables that are not given, or
+// whose filename is empty, are unavailable as they would be on a
+// server started without them.
+func NewLocal(filenames map[string]string) (*Local, error) {
+	strats := make(map[string]*optimization.Strategy, len(filenames))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	var firstErr error
+	for name, filename := range filenames {
+		if filename == "" {
+			continue
+		}
+
+		wg.Add(1)
+		go func(name, filename string) {
+			defer wg.Done()
+			strat, err := server.LoadTable(name, filename)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil && firstErr == nil {
+				firstErr = err
+			}
+			strats[name] = strat
+		}(name, filename)
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	return NewLocalFromStrategies(strats[server.TableScoreDistribution],
+		strats[server.TableExpectedValue], strats[server.TableExpectedWork]), nil
+}
+
+// NewLocalFromStrategies creates a Local advisor with the given
+// strategies. Any of them may be nil if it is not needed.
+func NewLocalFromStrategies(highScoreStrat, expectedScoreStrat, expectedWorkStrat *optimization.Strategy) *Local {
+	return &Local{server.NewYahtzeeServer(highScoreStrat, expectedScoreStrat, expectedWorkStrat)}
+}
+
+// GetOptimalMove implements Advisor.
+func (l *Local) GetOptimalMove(ctx context.Context, game yahtzee.GameState, step yahtzee.TurnStep,
+	roll []int, scoreToBeat, currentScore int) (*server.OptimalMoveResponse, error) {
+	return l.OptimalMove(ctx, optimalMoveRequest(game, step, roll, scoreToBeat, currentScore))
+}
+
+// GetGameValue implements Advisor.
+func (l *Local) GetGameValue(ctx context.Context, game yahtzee.GameState, scoreToBeat int) (float32, error) {
+	resp, err := l.GetOptimalMove(ctx, game, yahtzee.Begin, nil, scoreToBeat, 0)
+	if err != nil {
+		return 0, err
+	}
+
+	return resp.Value, nil
+}
+
+// OptimalMove implements Advisor.
+func (l *Local) OptimalMove(ctx context.Context, req *server.OptimalMoveRequest) (*server.OptimalMoveResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	resp, err := l.ys.ComputeOptimalMove(req)
+	return resp, localError(err)
+}
+
+// OutcomeDistribution implements Advisor.
+func (l *Local) OutcomeDistribution(ctx context.Context,
+	req *server.OutcomeDistributionRequest) (*server.OutcomeDistributionResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	resp, err := l.ys.ComputeOutcomeDistribution(req)
+	return resp, localError(err)
+}
+
+// Explain implements Advisor.
+func (l *Local) Explain(ctx context.Context, req *server.ExplainRequest) (*server.ExplainResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	resp, err := l.ys.ComputeExplanation(req)
+	return resp, localError(err)
+}
+
+// localError converts errors from the server into the *Error a
+// Client would have returned for the same request.
+func localError(err error) error {
+	if apiErr, ok := err.(*server.APIError); ok {
+		return &Error{apiErr.StatusCode(), server.APIError{
+			Code:    apiErr.Code,
+			Field:   apiErr.Field,
+			Message: apiErr.Message,
+		}}
+	}
+
+	return err
+}
@@ -0,0 +1,79 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/timpalpant/yahtzee"
+	"github.com/timpalpant/yahtzee/optimization"
+	"github.com/timpalpant/yahtzee/server"
+)
+
+func TestLocalMatchesClient(t *testing.T) {
+	ts := newTestServer()
+	defer ts.Close()
+	ctx := context.Background()
+	advisors := []Advisor{
+		NewClient(WithBaseURL(ts.URL)),
+		NewLocalFromStrategies(
+			optimization.NewStrategy(optimization.NewScoreDistribution()),
+			optimization.NewStrategy(optimization.NewExpectedValue()),
+			nil),
+	}
+
+	roll := []int{2, 3, 5, 5, 6}
+	var moves []*server.OptimalMoveResponse
+	var explanations []*server.ExplainResponse
+	var errs []error
+	for _, advisor := range advisors {
+		move, err := advisor.GetOptimalMove(ctx, lateGame, yahtzee.Hold1, roll, 0, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		moves = append(moves, move)
+
+		explanation, err := advisor.Explain(ctx, &server.ExplainRequest{
+			GameState:   server.FromYahtzeeGameState(lateGame),
+			TurnState:   server.TurnState{Step: yahtzee.Hold2, Dice: roll},
+			ScoreToBeat: 20,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		explanations = append(explanations, explanation)
+
+		// Requires the expected work table, which is unavailable.
+		_, err = advisor.GetGameValue(ctx, lateGame, 20)
+		errs = append(errs, err)
+	}
+
+	if !reflect.DeepEqual(moves[0], moves[1]) {
+		t.Errorf("GetOptimalMove() = %+v locally, expected %+v", moves[1], moves[0])
+	}
+	// Choices with the same rank may be in any order.
+	for _, explanation := range explanations {
+		holds := explanation.HoldChoices
+		sort.Slice(holds, func(i, j int) bool {
+			return fmt.Sprint(holds[i].HeldDice) < fmt.Sprint(holds[j].HeldDice)
+		})
+	}
+	if !reflect.DeepEqual(explanations[0], explanations[1]) {
+		t.Errorf("Explain() = %+v locally, expected %+v", explanations[1], explanations[0])
+	}
+	if !reflect.DeepEqual(errs[0], errs[1]) {
+		t.Errorf("GetGameValue() failed with %v locally, expected %v", errs[1], errs[0])
+	}
+}
+
+func TestLocalCanceled(t *testing.T) {
+	local := NewLocalFromStrategies(nil, nil, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := local.GetOptimalMove(ctx, lateGame, yahtzee.Hold1, []int{1, 2, 3, 4, 5}, 0, 0); err != context.Canceled {
+		t.Errorf("GetOptimalMove() returned %v, expected %v", err, context.Canceled)
+	}
+}
@@ -2,90 +2,362 @@ package client
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
+	"math/rand"
+	"net"
 	"net/http"
+	"time"
+
+	"github.com/golang/glog"
 
 	"github.com/timpalpant/yahtzee"
 	"github.com/timpalpant/yahtzee/server"
 )
 
+const (
+	// DefaultBaseURL is the URL of a Yahtzee server running locally
+	// with the default port.
+	DefaultBaseURL = "http://localhost:8080"
+	// DefaultMaxBackoff is the maximum delay between retries.
+	DefaultMaxBackoff = 10 * time.Second
+)
+
+// Error is returned when the server responds with an error status.
+type Error struct {
+	StatusCode int
+	server.APIError
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("request returned %d: %v", e.StatusCode, e.APIError.Error())
+}
+
+// Temporary returns true if a request that failed with err may
+// succeed if it is retried: if the server could not be reached, or
+// failed with a 5xx status (e.g. while its tables are loading).
+func Temporary(err error) bool {
+	if apiErr, ok := err.(*Error); ok {
+		return apiErr.StatusCode >= http.StatusInternalServerError
+	}
+
+	_, ok := err.(net.Error)
+	return ok
+}
+
+// decodeError reads the ErrorResponse from a failed request.
+func decodeError(resp *http.Response) error {
+	result := &server.ErrorResponse{}
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		// Not a structured error, e.g. from a proxy.
+		result.Error.Message = resp.Status
+	}
+
+	return &Error{resp.StatusCode, result.Error}
+}
+
+// Client makes requests to a Yahtzee server.
 type Client struct {
-	client *http.Client
-	uri    string
+	httpClient *http.Client
+	baseURL    string
+	// timeout is the maximum duration of each attempt of a request.
+	timeout time.Duration
+	// maxRetries is the number of times a failed request is retried.
+	maxRetries int
+	// backoff is the delay before the first retry, which doubles
+	// with each subsequent retry up to maxBackoff.
+	backoff    time.Duration
+	maxBackoff time.Duration
+}
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithHTTPClient sets the http.Client used to make requests.
+// The default is http.DefaultClient.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = hc
+	}
+}
+
+// WithBaseURL sets the URL of the server, e.g. "http://localhost:8080".
+func WithBaseURL(uri string) Option {
+	return func(c *Client) {
+		c.baseURL = uri
+	}
+}
+
+// WithTimeout limits the duration of each attempt of a request.
+// Contexts passed to the client may further limit the total
+// duration of the request, including retries.
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.timeout = timeout
+	}
 }
 
-func NewClient(uri string) *Client {
-	return &Client{http.DefaultClient, uri}
+// WithRetries retries requests that fail with a connection error or a
+// 5xx status up to maxRetries times. The delay before the first retry
+// is backoff, which doubles with each subsequent retry.
+func WithRetries(maxRetries int, backoff time.Duration) Option {
+	return func(c *Client) {
+		c.maxRetries = maxRetries
+		c.backoff = backoff
+	}
+}
+
+// WithMaxBackoff limits the delay between retries.
+func WithMaxBackoff(maxBackoff time.Duration) Option {
+	return func(c *Client) {
+		c.maxBackoff = maxBackoff
+	}
 }
 
-func (c *Client) GetOptimalMove(game yahtzee.GameState, step yahtzee.TurnStep, roll []int, scoreToBeat int) (*server.OptimalMoveResponse, error) {
-	req := &server.OptimalMoveRequest{
+// NewClient creates a client with the given options. By default,
+// requests are made to DefaultBaseURL without a timeout or retries.
+func NewClient(opts ...Option) *Client {
+	c := &Client{
+		httpClient: http.DefaultClient,
+		baseURL:    DefaultBaseURL,
+		maxBackoff: DefaultMaxBackoff,
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// GetScore returns the score of playing the given dice in box,
+// not including any bonuses.
+func (c *Client) GetScore(ctx context.Context, dice []int, box yahtzee.Box) (int, error) {
+	req := &server.GetScoreRequest{Dice: dice, Box: int(box)}
+	result := &server.GetScoreResponse{}
+	if err := c.post(ctx, "/rest/v1/score", req, result); err != nil {
+		return 0, err
+	}
+
+	return result.Score, nil
+}
+
+// OptimalMove returns the best move for the position in req.
+func (c *Client) OptimalMove(ctx context.Context, req *server.OptimalMoveRequest) (*server.OptimalMoveResponse, error) {
+	result := &server.OptimalMoveResponse{}
+	if err := c.post(ctx, "/rest/v1/optimal_move", req, result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// GetOptimalMove returns the best move for the given roll. If scoreToBeat
+// (the score needed over the remaining turns) is provided, currentScore is
+// used to decide whether it would be better to start a new game.
+func (c *Client) GetOptimalMove(ctx context.Context, game yahtzee.GameState, step yahtzee.TurnStep,
+	roll []int, scoreToBeat, currentScore int) (*server.OptimalMoveResponse, error) {
+	return c.OptimalMove(ctx, optimalMoveRequest(game, step, roll, scoreToBeat, currentScore))
+}
+
+func optimalMoveRequest(game yahtzee.GameState, step yahtzee.TurnStep,
+	roll []int, scoreToBeat, currentScore int) *server.OptimalMoveRequest {
+	return &server.OptimalMoveRequest{
 		GameState: server.FromYahtzeeGameState(game),
 		TurnState: server.TurnState{
 			Step: step,
 			Dice: roll,
 		},
-		ScoreToBeat: scoreToBeat,
+		ScoreToBeat:  scoreToBeat,
+		CurrentScore: currentScore,
 	}
+}
 
-	b := new(bytes.Buffer)
-	enc := json.NewEncoder(b)
-	if err := enc.Encode(req); err != nil {
+// GetGameValue returns the value of the game at the beginning of a turn:
+// the expected remaining score if scoreToBeat is 0, and the negative
+// expected work to achieve scoreToBeat otherwise.
+func (c *Client) GetGameValue(ctx context.Context, game yahtzee.GameState, scoreToBeat int) (float32, error) {
+	resp, err := c.GetOptimalMove(ctx, game, yahtzee.Begin, nil, scoreToBeat, 0)
+	if err != nil {
+		return 0, err
+	}
+
+	return resp.Value, nil
+}
+
+// OutcomeDistribution returns the distribution of final scores
+// for every choice in the position in req.
+func (c *Client) OutcomeDistribution(ctx context.Context,
+	req *server.OutcomeDistributionRequest) (*server.OutcomeDistributionResponse, error) {
+	result := &server.OutcomeDistributionResponse{}
+	if err := c.post(ctx, "/rest/v1/outcome_distribution", req, result); err != nil {
 		return nil, err
 	}
 
-	endpoint := c.uri + "/rest/v1/optimal_move"
-	resp, err := http.Post(endpoint, "application/json; charset=utf-8", b)
+	return result, nil
+}
+
+// Explain ranks and explains every choice in the position in req.
+func (c *Client) Explain(ctx context.Context, req *server.ExplainRequest) (*server.ExplainResponse, error) {
+	result := &server.ExplainResponse{}
+	if err := c.post(ctx, "/rest/v1/explain", req, result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// BatchOptimalMove returns the best move for many positions.
+// The results are in the same order as the requests. Positions that
+// could not be evaluated have their BatchResult.Error set.
+func (c *Client) BatchOptimalMove(ctx context.Context, reqs []server.OptimalMoveRequest) ([]server.BatchResult, error) {
+	var results []server.BatchResult
+	err := c.do(ctx, http.MethodPost, "/rest/v1/batch/optimal_move", reqs, true, func(r io.Reader) error {
+		results = results[:0]
+		dec := json.NewDecoder(r)
+		for {
+			var result server.BatchResult
+			if err := dec.Decode(&result); err == io.EOF {
+				return nil
+			} else if err != nil {
+				return err
+			}
+			results = append(results, result)
+		}
+	})
 	if err != nil {
 		return nil, err
+	} else if len(results) != len(reqs) {
+		return nil, fmt.Errorf("batch returned %d results for %d requests", len(results), len(reqs))
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("request returned: %v", resp.Status)
+	return results, nil
+}
+
+// Status returns the status of the server and its strategy tables.
+func (c *Client) Status(ctx context.Context) (*server.ServerStatus, error) {
+	result := &server.ServerStatus{}
+	if err := c.get(ctx, "/healthz", result); err != nil {
+		return nil, err
 	}
 
-	result := &server.OptimalMoveResponse{}
-	dec := json.NewDecoder(resp.Body)
-	if err := dec.Decode(result); err != nil {
+	return result, nil
+}
+
+// Reload reloads the strategy tables of the server. The server must
+// be run with -enable_admin, and files must be in its -table_dir.
+func (c *Client) Reload(ctx context.Context, req *server.ReloadRequest) (*server.ReloadResponse, error) {
+	result := &server.ReloadResponse{}
+	if err := c.do(ctx, http.MethodPost, "/admin/reload", req, false, decodeJSON(result)); err != nil {
 		return nil, err
 	}
 
-	return result, err
+	return result, nil
 }
 
-func (c *Client) GetGameValue(game yahtzee.GameState, scoreToBeat int) (float32, error) {
-	req := &server.OptimalMoveRequest{
-		GameState: server.FromYahtzeeGameState(game),
-		TurnState: server.TurnState{
-			Step: yahtzee.Begin,
-		},
-		ScoreToBeat: scoreToBeat,
+// OpenAPI returns the OpenAPI document describing the server's API.
+func (c *Client) OpenAPI(ctx context.Context) (map[string]interface{}, error) {
+	var result map[string]interface{}
+	if err := c.get(ctx, "/openapi.json", &result); err != nil {
+		return nil, err
 	}
 
-	b := new(bytes.Buffer)
-	enc := json.NewEncoder(b)
-	if err := enc.Encode(req); err != nil {
-		return 0, err
+	return result, nil
+}
+
+// post sends req as JSON to the given path, and decodes the response into result.
+// The request is retried if it fails, so it must be idempotent.
+func (c *Client) post(ctx context.Context, path string, req, result interface{}) error {
+	return c.do(ctx, http.MethodPost, path, req, true, decodeJSON(result))
+}
+
+// get decodes the response to a GET request for the given path into result.
+func (c *Client) get(ctx context.Context, path string, result interface{}) error {
+	return c.do(ctx, http.MethodGet, path, nil, true, decodeJSON(result))
+}
+
+// decodeJSON returns a function that decodes a JSON response into result.
+func decodeJSON(result interface{}) func(io.Reader) error {
+	return func(r io.Reader) error {
+		return json.NewDecoder(r).Decode(result)
+	}
+}
+
+// do makes a request, and decodes a successful response with decode.
+// If body is not nil, it is sent encoded as JSON. If retry is true,
+// the request is retried if it fails with a connection error or a
+// 5xx status.
+func (c *Client) do(ctx context.Context, method, path string, body interface{},
+	retry bool, decode func(io.Reader) error) error {
+	var data []byte
+	if body != nil {
+		var err error
+		if data, err = json.Marshal(body); err != nil {
+			return err
+		}
+	}
+
+	backoff := c.backoff
+	for attempt := 0; ; attempt++ {
+		retryable, err := c.attempt(ctx, method, path, data, decode)
+		if !retry || !retryable || attempt >= c.maxRetries {
+			return err
+		}
+
+		delay := backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1))
+		glog.Warningf("%v %v failed, retrying in %v: %v", method, path, delay, err)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+
+		if backoff *= 2; backoff > c.maxBackoff {
+			backoff = c.maxBackoff
+		}
+	}
+}
+
+// attempt makes a single attempt of a request, and returns whether
+// it failed in a way that should be retried.
+func (c *Client) attempt(ctx context.Context, method, path string, data []byte,
+	decode func(io.Reader) error) (bool, error) {
+	parent := ctx
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
 	}
 
-	endpoint := c.uri + "/rest/v1/optimal_move"
-	resp, err := http.Post(endpoint, "application/json; charset=utf-8", b)
+	var body io.Reader
+	if data != nil {
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, c.baseURL+path, body)
 	if err != nil {
-		return 0, err
+		return false, err
+	}
+	req = req.WithContext(ctx)
+	if data != nil {
+		req.Header.Set("Content-Type", "application/json; charset=utf-8")
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return 0, fmt.Errorf("request returned: %v", resp.Status)
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		// Connection errors and timeouts of this attempt are
+		// retried, unless the caller has given up.
+		return parent.Err() == nil, err
 	}
+	defer resp.Body.Close()
 
-	result := &server.OptimalMoveResponse{}
-	dec := json.NewDecoder(resp.Body)
-	if err := dec.Decode(result); err != nil {
-		return 0, err
+	if resp.StatusCode >= http.StatusBadRequest {
+		return resp.StatusCode >= http.StatusInternalServerError, decodeError(resp)
+	} else if decode == nil || resp.StatusCode == http.StatusNoContent {
+		return false, nil
 	}
 
-	return result.Value, err
+	return false, decode(resp.Body)
 }
@@ -0,0 +1,303 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/timpalpant/yahtzee"
+	"github.com/timpalpant/yahtzee/optimization"
+	"github.com/timpalpant/yahtzee/server"
+)
+
+// lateGame has only Chance and Yahtzee open, so that the strategy
+// tables can be computed quickly on demand.
+var lateGame = func() yahtzee.GameState {
+	game := yahtzee.NewGame()
+	for box := yahtzee.Ones; box < yahtzee.Chance; box++ {
+		game = game.SetBoxFilled(box)
+	}
+	return game.AddUpperHalfScore(50)
+}()
+
+// newTestServer starts a server backed by strategies that are computed
+// on demand. Requests must only use late game states.
+func newTestServer() *httptest.Server {
+	ys := server.NewYahtzeeServer(
+		optimization.NewStrategy(optimization.NewScoreDistribution()),
+		optimization.NewStrategy(optimization.NewExpectedValue()),
+		nil)
+	ys.SetTableDir(os.TempDir())
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/rest/v1/score", ys.GetScore)
+	mux.HandleFunc("/rest/v1/optimal_move", ys.OptimalMove)
+	mux.HandleFunc("/rest/v1/outcome_distribution", ys.OutcomeDistribution)
+	mux.HandleFunc("/rest/v1/explain", ys.Explain)
+	mux.HandleFunc("/rest/v1/batch/optimal_move", ys.BatchOptimalMove)
+	mux.HandleFunc("/rest/v1/games", ys.Games)
+	mux.HandleFunc("/rest/v1/games/", ys.Games)
+	mux.HandleFunc("/rest/v1/live", ys.LiveAdvice)
+	mux.HandleFunc("/healthz", ys.Healthz)
+	mux.HandleFunc("/admin/reload", ys.ReloadTables)
+	mux.HandleFunc("/openapi.json", ys.OpenAPI)
+	return httptest.NewServer(mux)
+}
+
+func TestRoundTrip(t *testing.T) {
+	ts := newTestServer()
+	defer ts.Close()
+	c := NewClient(WithBaseURL(ts.URL))
+	ctx := context.Background()
+	roll := []int{1, 2, 3, 6, 6}
+
+	if score, err := c.GetScore(ctx, roll, yahtzee.Sixes); err != nil {
+		t.Error(err)
+	} else if score != 12 {
+		t.Errorf("GetScore() = %v, expected 12", score)
+	}
+
+	if move, err := c.GetOptimalMove(ctx, lateGame, yahtzee.Hold1, roll, 0, 0); err != nil {
+		t.Error(err)
+	} else if len(move.HeldDice) != 2 || move.Value != move.Values.ExpectedValue {
+		t.Errorf("GetOptimalMove() = %+v, expected to hold 6s", move)
+	}
+
+	position := server.FromYahtzeeGameState(lateGame)
+	turn := server.TurnState{Step: yahtzee.Hold2, Dice: roll}
+	if outcomes, err := c.OutcomeDistribution(ctx, &server.OutcomeDistributionRequest{
+		GameState: position, TurnState: turn, Compact: true,
+	}); err != nil {
+		t.Error(err)
+	} else if len(outcomes.HoldChoices) != 24 || len(outcomes.FillChoices) != 2 {
+		t.Errorf("OutcomeDistribution() returned %d holds and %d fills, expected 24 and 2",
+			len(outcomes.HoldChoices), len(outcomes.FillChoices))
+	}
+
+	if explanation, err := c.Explain(ctx, &server.ExplainRequest{
+		GameState: position, TurnState: turn, ScoreToBeat: 30,
+	}); err != nil {
+		t.Error(err)
+	} else if explanation.HoldChoices[0].Rank != 1 {
+		t.Errorf("Explain() best hold = %+v, expected rank 1", explanation.HoldChoices[0])
+	}
+
+	results, err := c.BatchOptimalMove(ctx, []server.OptimalMoveRequest{
+		{GameState: position, TurnState: turn},
+		{GameState: position, TurnState: server.TurnState{Step: yahtzee.FillBox, Dice: []int{7}}},
+	})
+	if err != nil {
+		t.Error(err)
+	} else if results[0].Response == nil || results[1].Error == nil ||
+		results[1].Error.Code != server.ErrCodeInvalidDice {
+		t.Errorf("BatchOptimalMove() = %+v, expected a response and an invalid_dice error", results)
+	}
+
+	if status, err := c.Status(ctx); err != nil {
+		t.Error(err)
+	} else if !status.Ready {
+		t.Errorf("Status() = %+v, expected ready", status)
+	}
+
+	if resp, err := c.Reload(ctx, &server.ReloadRequest{
+		Tables: map[string]string{server.TableExpectedValue: "nonexistent.gob.gz"},
+	}); err != nil {
+		t.Error(err)
+	} else if len(resp.Tables) != 1 || resp.Tables[0].Error == nil {
+		t.Errorf("Reload() = %+v, expected an error loading the table", resp)
+	}
+
+	if doc, err := c.OpenAPI(ctx); err != nil {
+		t.Error(err)
+	} else if doc["openapi"] == nil {
+		t.Errorf("OpenAPI() = %v, expected an OpenAPI document", doc)
+	}
+}
+
+func TestGameSession(t *testing.T) {
+	ts := newTestServer()
+	defer ts.Close()
+	c := NewClient(WithBaseURL(ts.URL))
+	ctx := context.Background()
+
+	gs, err := c.CreateGame(ctx, 200)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	steps := []func() (*server.GameSession, error){
+		func() (*server.GameSession, error) { return c.Roll(ctx, gs.ID, []int{1, 2, 6, 6, 6}) },
+		func() (*server.GameSession, error) { return c.Hold(ctx, gs.ID, []int{6, 6, 6}) },
+		func() (*server.GameSession, error) { return c.Undo(ctx, gs.ID) },
+		func() (*server.GameSession, error) { return c.Fill(ctx, gs.ID, yahtzee.Sixes) },
+		func() (*server.GameSession, error) { return c.GetGame(ctx, gs.ID) },
+	}
+	for _, step := range steps {
+		if gs, err = step(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if gs.Scorecard.Total != 18 {
+		t.Errorf("Total = %v, expected 18", gs.Scorecard.Total)
+	}
+
+	if err := c.DeleteGame(ctx, gs.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = c.GameAdvice(ctx, gs.ID)
+	if apiErr, ok := err.(*Error); !ok || apiErr.StatusCode != http.StatusNotFound {
+		t.Errorf("GameAdvice() error = %v, expected 404 for deleted game", err)
+	}
+}
+
+func TestLiveAdvice(t *testing.T) {
+	ts := newTestServer()
+	defer ts.Close()
+	c := NewClient(WithBaseURL(ts.URL))
+
+	conn, err := c.LiveAdvice(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	req := &server.LiveAdviceRequest{
+		GameState:   server.FromYahtzeeGameState(lateGame),
+		TurnState:   server.TurnState{Step: yahtzee.Hold1, Dice: []int{1, 2, 3, 6, 6}},
+		ScoreToBeat: 30,
+	}
+	if err := conn.Send(req); err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := conn.Receive()
+	if err != nil {
+		t.Fatal(err)
+	} else if resp.Error != nil {
+		t.Fatal(resp.Error)
+	} else if len(resp.HoldChoices) != 24 {
+		t.Errorf("received %d hold choices, expected 24", len(resp.HoldChoices))
+	}
+
+	// Positions without dice are answered, and invalid ones with an
+	// error, but dice that are still being entered are not.
+	req.TurnState = server.TurnState{Step: yahtzee.Begin}
+	if err := conn.Send(req); err != nil {
+		t.Fatal(err)
+	} else if resp, err := conn.Receive(); err != nil {
+		t.Fatal(err)
+	} else if resp.TurnState.Step != yahtzee.Begin || resp.Error != nil {
+		t.Errorf("received %+v, expected advice at step Begin", resp)
+	}
+
+	for _, ts := range []server.TurnState{
+		{Step: yahtzee.Hold1, Dice: []int{1, 2}},
+		{Step: yahtzee.Hold1, HeldDice: []int{6, 6}},
+	} {
+		req.TurnState = ts
+		if err := conn.Send(req); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if resp, err := conn.Receive(); err != nil {
+		t.Fatal(err)
+	} else if resp.TurnState.HeldDice == nil || resp.Error == nil || resp.Error.Code != server.ErrCodeInvalidDice {
+		t.Errorf("received %+v, expected %v error for held dice without a roll", resp, server.ErrCodeInvalidDice)
+	}
+}
+
+func TestRetriesServerErrors(t *testing.T) {
+	var attempts int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			json.NewEncoder(w).Encode(server.ErrorResponse{
+				Error: server.APIError{Code: server.ErrCodeUnavailable, Message: "loading"},
+			})
+			return
+		}
+
+		json.NewEncoder(w).Encode(server.OptimalMoveResponse{Value: 254.59})
+	}))
+	defer ts.Close()
+
+	c := NewClient(WithBaseURL(ts.URL), WithRetries(3, time.Millisecond))
+	value, err := c.GetGameValue(context.Background(), yahtzee.NewGame(), 0)
+	if err != nil {
+		t.Fatal(err)
+	} else if value != 254.59 {
+		t.Errorf("GetGameValue() = %v, expected 254.59", value)
+	} else if attempts != 3 {
+		t.Errorf("made %d attempts, expected 3", attempts)
+	}
+}
+
+func TestDoesNotRetryClientErrors(t *testing.T) {
+	var attempts int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(server.ErrorResponse{
+			Error: server.APIError{Code: server.ErrCodeInvalidDice, Message: "invalid dice"},
+		})
+	}))
+	defer ts.Close()
+
+	c := NewClient(WithBaseURL(ts.URL), WithRetries(3, time.Millisecond))
+	_, err := c.GetOptimalMove(context.Background(), yahtzee.NewGame(), yahtzee.Hold1, []int{7}, 0, 0)
+	if apiErr, ok := err.(*Error); !ok || apiErr.StatusCode != http.StatusBadRequest ||
+		apiErr.Code != server.ErrCodeInvalidDice {
+		t.Errorf("GetOptimalMove() error = %v, expected invalid_dice", err)
+	} else if attempts != 1 {
+		t.Errorf("made %d attempts, expected 1", attempts)
+	}
+}
+
+func TestTemporary(t *testing.T) {
+	testCases := []struct {
+		err      error
+		expected bool
+	}{
+		{&Error{StatusCode: http.StatusServiceUnavailable}, true},
+		{&Error{StatusCode: http.StatusInternalServerError}, true},
+		{&Error{StatusCode: http.StatusBadRequest}, false},
+		{&Error{StatusCode: http.StatusNotFound}, false},
+		{&url.Error{Op: "Post", URL: DefaultBaseURL, Err: errors.New("connection refused")}, true},
+		{context.Canceled, false},
+		{errors.New("invalid response"), false},
+	}
+
+	for _, tc := range testCases {
+		if result := Temporary(tc.err); result != tc.expected {
+			t.Errorf("Temporary(%v) = %v, expected %v", tc.err, result, tc.expected)
+		}
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer ts.Close()
+
+	c := NewClient(WithBaseURL(ts.URL), WithTimeout(10*time.Millisecond))
+	start := time.Now()
+	if _, err := c.GetGameValue(context.Background(), yahtzee.NewGame(), 0); err == nil {
+		t.Error("expected request to time out")
+	} else if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("request took %v, expected timeout after 10ms", elapsed)
+	}
+}
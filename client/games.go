@@ -0,0 +1,84 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"github.com/timpalpant/yahtzee"
+	"github.com/timpalpant/yahtzee/server"
+)
+
+// Requests that change a game session are not retried, since a request
+// that failed with a connection error may have been applied.
+
+// CreateGame starts a new game session. If scoreToBeat is provided,
+// advice for the session maximizes the probability of beating it.
+func (c *Client) CreateGame(ctx context.Context, scoreToBeat int) (*server.GameSession, error) {
+	req := &server.CreateGameRequest{ScoreToBeat: scoreToBeat}
+	return c.updateGame(ctx, http.MethodPost, "/rest/v1/games", req)
+}
+
+// GetGame returns the current state of a game session.
+func (c *Client) GetGame(ctx context.Context, id string) (*server.GameSession, error) {
+	result := &server.GameSession{}
+	if err := c.get(ctx, gamePath(id, ""), result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// DeleteGame ends a game session.
+func (c *Client) DeleteGame(ctx context.Context, id string) error {
+	return c.do(ctx, http.MethodDelete, gamePath(id, ""), nil, false, nil)
+}
+
+// Roll records the dice rolled in a game session, including any held dice.
+func (c *Client) Roll(ctx context.Context, id string, dice []int) (*server.GameSession, error) {
+	return c.updateGame(ctx, http.MethodPost, gamePath(id, "roll"), &server.RollRequest{Dice: dice})
+}
+
+// Hold records the dice held before the next roll in a game session.
+func (c *Client) Hold(ctx context.Context, id string, held []int) (*server.GameSession, error) {
+	return c.updateGame(ctx, http.MethodPost, gamePath(id, "hold"), &server.HoldRequest{HeldDice: held})
+}
+
+// Fill plays the current dice of a game session in the given box.
+func (c *Client) Fill(ctx context.Context, id string, box yahtzee.Box) (*server.GameSession, error) {
+	return c.updateGame(ctx, http.MethodPost, gamePath(id, "fill"), &server.FillRequest{Box: int(box)})
+}
+
+// Undo undoes the last roll, hold or fill in a game session.
+func (c *Client) Undo(ctx context.Context, id string) (*server.GameSession, error) {
+	return c.updateGame(ctx, http.MethodPost, gamePath(id, "undo"), nil)
+}
+
+// GameAdvice returns the best move in the current state of a game session.
+func (c *Client) GameAdvice(ctx context.Context, id string) (*server.OptimalMoveResponse, error) {
+	result := &server.OptimalMoveResponse{}
+	if err := c.get(ctx, gamePath(id, "advice"), result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (c *Client) updateGame(ctx context.Context, method, path string, req interface{}) (*server.GameSession, error) {
+	result := &server.GameSession{}
+	if err := c.do(ctx, method, path, req, false, decodeJSON(result)); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// gamePath returns the path of the given action on a game session.
+func gamePath(id, action string) string {
+	path := "/rest/v1/games/" + url.PathEscape(id)
+	if action != "" {
+		path += "/" + action
+	}
+
+	return path
+}
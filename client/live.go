@@ -0,0 +1,55 @@
+package client
+
+import (
+	"context"
+	"strings"
+
+	"github.com/timpalpant/yahtzee/server"
+	"github.com/timpalpant/yahtzee/server/websocket"
+)
+
+// LiveAdviceConn is a connection to the live advice WebSocket.
+// Send and Receive may be called concurrently, but only one goroutine
+// may send and one goroutine may receive at a time.
+type LiveAdviceConn struct {
+	conn *websocket.Conn
+}
+
+// LiveAdvice connects to the live advice WebSocket of the server.
+// The context only applies to establishing the connection.
+func (c *Client) LiveAdvice(ctx context.Context) (*LiveAdviceConn, error) {
+	uri := c.baseURL + "/rest/v1/live"
+	if strings.HasPrefix(uri, "http") {
+		uri = "ws" + strings.TrimPrefix(uri, "http")
+	}
+
+	conn, err := websocket.Dial(ctx, uri)
+	if err != nil {
+		return nil, err
+	}
+
+	return &LiveAdviceConn{conn}, nil
+}
+
+// Send sends the current dice to the server. Requests with fewer than
+// 5 dice are ignored by the server. If a new request is sent before
+// the response to the previous one, only the latest may be answered.
+func (lc *LiveAdviceConn) Send(req *server.LiveAdviceRequest) error {
+	return lc.conn.WriteJSON(req)
+}
+
+// Receive waits for the next response from the server.
+// If the request failed, the error is returned in the response.
+func (lc *LiveAdviceConn) Receive() (*server.LiveAdviceResponse, error) {
+	resp := &server.LiveAdviceResponse{}
+	if err := lc.conn.ReadJSON(resp); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// Close closes the connection.
+func (lc *LiveAdviceConn) Close() error {
+	return lc.conn.Close()
+}
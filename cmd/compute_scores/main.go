@@ -36,7 +36,7 @@ func main() {
 	case "expected_work":
 		obs = optimization.NewExpectedWork(10000)
 	default:
-		glog.Fatal("Unknown observable: %v, options: expected_value, score_distribution")
+		glog.Fatalf("Unknown observable: %v, options: expected_value, score_distribution, expected_work", *observable)
 	}
 
 	var s *optimization.Strategy
@@ -2,14 +2,16 @@ package main
 
 import (
 	"bufio"
+	"context"
 	"flag"
 	"fmt"
 	"os"
-	"strconv"
 	"strings"
+	"time"
 
 	"github.com/timpalpant/yahtzee"
 	"github.com/timpalpant/yahtzee/client"
+	"github.com/timpalpant/yahtzee/server"
 )
 
 var stdin = bufio.NewReader(os.Stdin)
@@ -24,31 +26,32 @@ func prompt(msg string) string {
 	return strings.TrimRight(result, "\n")
 }
 
-func parseRoll(s string) (yahtzee.Roll, error) {
-	roll := yahtzee.NewRoll()
-
-	i, err := strconv.Atoi(s)
-	if err != nil {
-		return roll, err
-	}
-
-	for ; i > 0; i /= 10 {
-		die := i % 10
-		roll = roll.Add(die)
-	}
-
-	if roll.NumDice() != yahtzee.NDice {
-		return roll, fmt.Errorf("Invalid number of dice: %v != %v",
-			roll.NumDice(), yahtzee.NDice)
+// parseRoll parses the dice that were rolled, e.g. "256" or "2 5 6"
+// (see yahtzee.ParseDice), and adds them to the held dice. Together
+// they must be a complete roll (see yahtzee.ParseRoll).
+func parseRoll(s string, held yahtzee.Roll) (yahtzee.Roll, error) {
+	heldDice := strings.Trim(fmt.Sprint(held.Dice()), "[]")
+	roll, err := yahtzee.ParseRoll(heldDice + " " + s)
+	if err != nil && held.NumDice() > 0 {
+		return held, fmt.Errorf("%v, including held dice %v", err, held.Dice())
+	} else if err != nil {
+		return held, err
 	}
 
 	return roll, nil
 }
 
-func promptRoll() yahtzee.Roll {
+// promptRoll asks for the dice that were rolled, which are
+// only those that were not held.
+func promptRoll(held yahtzee.Roll) yahtzee.Roll {
+	msg := "Enter roll: "
+	if n := held.NumDice(); n > 0 {
+		msg = fmt.Sprintf("Holding %v, enter the other %d dice: ", held.Dice(), yahtzee.NDice-n)
+	}
+
 	for {
-		rollStr := prompt("Enter roll: ")
-		roll, err := parseRoll(rollStr)
+		rollStr := prompt(msg)
+		roll, err := parseRoll(rollStr, held)
 		if err != nil {
 			fmt.Printf("Invalid roll: %v\n", err)
 			continue
@@ -58,60 +61,121 @@ func promptRoll() yahtzee.Roll {
 	}
 }
 
-func playGame(uri string, scoreToBeat int) {
+// getOptimalMove asks the advisor for the best move. If it fails with
+// a temporary error (e.g. the server is unavailable), it is retried
+// when the user is ready, so that the rolls entered so far this turn
+// are not lost. Other errors will not succeed if retried, and are returned.
+func getOptimalMove(ctx context.Context, advisor client.Advisor, game yahtzee.GameState,
+	step yahtzee.TurnStep, roll yahtzee.Roll, scoreToBeat, currentScore int) (*server.OptimalMoveResponse, error) {
+	for {
+		resp, err := advisor.GetOptimalMove(ctx, game, step, roll.Dice(), scoreToBeat, currentScore)
+		if err == nil {
+			return resp, nil
+		} else if !client.Temporary(err) {
+			return nil, err
+		}
+
+		fmt.Printf("Error getting optimal move: %v\n", err)
+		prompt("Press ENTER to try again: ")
+	}
+}
+
+func playGame(advisor client.Advisor, scoreToBeat int) error {
 	fmt.Println("Welcome to YAHTZEE!")
-	client := client.NewClient(uri)
+	ctx := context.Background()
 	game := yahtzee.NewGame()
 	var currentScore int
 
 	for !game.GameOver() {
-		roll1 := promptRoll()
-		resp1, err := client.GetOptimalMove(game, yahtzee.Hold1, roll1.Dice(), scoreToBeat)
-		if err != nil {
-			fmt.Println(err)
-			continue
+		remainingScore := scoreToBeat - currentScore
+		if remainingScore < 0 {
+			remainingScore = 0
 		}
-		fmt.Printf("Best option is to hold: %v, value: %g\n",
-			resp1.HeldDice, resp1.Value)
 
-		roll2 := promptRoll()
-		resp2, err := client.GetOptimalMove(game, yahtzee.Hold2, roll2.Dice(), scoreToBeat)
-		if err != nil {
-			fmt.Println(err)
-			continue
+		// Check whether it is better to start over before each turn
+		// (but not before the first one, when the game is already new).
+		if remainingScore > 0 && game != yahtzee.NewGame() {
+			resp, err := getOptimalMove(ctx, advisor, game, yahtzee.Begin, yahtzee.NewRoll(),
+				remainingScore, currentScore)
+			if err != nil {
+				return err
+			} else if resp.NewGame {
+				fmt.Printf("Margin = %g; best option is to give up and start a new game\n\n", *resp.NewGameMargin)
+				return nil
+			}
+		}
+
+		held := yahtzee.NewRoll()
+		var roll yahtzee.Roll
+		for step := yahtzee.Hold1; step < yahtzee.FillBox; step++ {
+			roll = promptRoll(held)
+			resp, err := getOptimalMove(ctx, advisor, game, step, roll, remainingScore, currentScore)
+			if err != nil {
+				return err
+			}
+			held = yahtzee.NewRollFromDice(resp.HeldDice)
+			if held == roll {
+				fmt.Printf("Best option is to keep all of the dice, value: %g\n", resp.Value)
+				break
+			}
+
+			fmt.Printf("Best option is to hold: %v, value: %g\n",
+				resp.HeldDice, resp.Value)
 		}
-		fmt.Printf("Best option is to hold: %v, value: %g\n",
-			resp2.HeldDice, resp2.Value)
 
-		roll3 := promptRoll()
-		resp3, err := client.GetOptimalMove(game, yahtzee.FillBox, roll3.Dice(), scoreToBeat)
+		if held != roll {
+			roll = promptRoll(held)
+		}
+
+		resp, err := getOptimalMove(ctx, advisor, game, yahtzee.FillBox, roll, remainingScore, currentScore)
 		if err != nil {
-			fmt.Println(err)
-			continue
+			return err
 		}
 
-		box := yahtzee.Box(resp3.BoxFilled)
+		box := yahtzee.Box(resp.BoxFilled)
 		var addValue int
-		game, addValue = game.FillBox(box, roll3)
+		game, addValue = game.FillBox(box, roll)
 		currentScore += addValue
-		if resp3.NewGame {
-			fmt.Printf("P = %g; best option is to give up and start a new game\n\n", resp3.Value)
-			return
-		} else {
-			fmt.Printf("Best option is to play: %v for %v points, final value: %g\n",
-				box, addValue, resp3.Value)
-		}
+		fmt.Printf("Best option is to play: %v for %v points, final value: %g\n",
+			box, addValue, resp.Value)
 	}
 
 	fmt.Printf("Game over! Final score: %v\n", currentScore)
+	return nil
 }
 
 func main() {
 	uri := flag.String("uri", "http://localhost:8080", "URI of Yahtzee server")
+	expectedScores := flag.String("expected_scores", "",
+		"File with expected scores to load, instead of using the Yahtzee server")
+	scoreDistributions := flag.String("score_distributions", "",
+		"File with score distributions to load, instead of using the Yahtzee server")
+	expectedWork := flag.String("expected_work", "",
+		"File with expected work distributions to load, instead of using the Yahtzee server")
 	scoreToBeat := flag.Int("score_to_beat", 0, "High score to try to beat")
 	flag.Parse()
 
+	var advisor client.Advisor
+	if *expectedScores != "" || *scoreDistributions != "" || *expectedWork != "" {
+		fmt.Println("Loading strategy tables...")
+		local, err := client.NewLocal(map[string]string{
+			server.TableExpectedValue:     *expectedScores,
+			server.TableScoreDistribution: *scoreDistributions,
+			server.TableExpectedWork:      *expectedWork,
+		})
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		advisor = local
+	} else {
+		advisor = client.NewClient(client.WithBaseURL(*uri), client.WithTimeout(time.Minute))
+	}
+
 	for {
-		playGame(*uri, *scoreToBeat)
+		if err := playGame(advisor, *scoreToBeat); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
 	}
 }
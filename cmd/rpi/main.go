@@ -2,6 +2,7 @@ package main
 
 import (
 	"bufio"
+	"context"
 	"flag"
 	"fmt"
 	"os"
@@ -14,6 +15,7 @@ import (
 	"github.com/timpalpant/yahtzee/rpi"
 	"github.com/timpalpant/yahtzee/rpi/controller"
 	"github.com/timpalpant/yahtzee/rpi/detector"
+	"github.com/timpalpant/yahtzee/server"
 )
 
 var stdin = bufio.NewReader(os.Stdin)
@@ -24,6 +26,14 @@ func main() {
 	annotate := flag.Bool("annotate", false, "Prompt for user roll input")
 	imageProcessingURI := flag.String("image_processing_uri", "", "URI of image processing server")
 	yahtzeeURI := flag.String("yahtzee_uri", "http://localhost:8085", "URI of Yahtzee server")
+	expectedScores := flag.String("expected_scores", "",
+		"File with expected scores to load, instead of using the Yahtzee server")
+	scoreDistributions := flag.String("score_distributions", "",
+		"File with score distributions to load, instead of using the Yahtzee server")
+	expectedWork := flag.String("expected_work", "",
+		"File with expected work distributions to load, instead of using the Yahtzee server")
+	timeout := flag.Duration("timeout", time.Minute, "Timeout for each request to the Yahtzee server")
+	retries := flag.Int("retries", 3, "Number of times to retry failed requests to the Yahtzee server")
 	scoreToBeat := flag.Int("score_to_beat", 0, "Score to beat (if 0, maximize expected score)")
 	playContinuously := flag.Bool("play_continuously", false, "Continue to next game automatically")
 	flag.Parse()
@@ -32,7 +42,24 @@ func main() {
 		glog.Fatal("-annotate and/or -image_processing_uri must be set")
 	}
 
-	client := client.NewClient(*yahtzeeURI)
+	var advisor client.Advisor
+	if *expectedScores != "" || *scoreDistributions != "" || *expectedWork != "" {
+		glog.Info("Loading strategy tables")
+		local, err := client.NewLocal(map[string]string{
+			server.TableExpectedValue:     *expectedScores,
+			server.TableScoreDistribution: *scoreDistributions,
+			server.TableExpectedWork:      *expectedWork,
+		})
+		if err != nil {
+			glog.Fatal(err)
+		}
+		advisor = local
+	} else {
+		advisor = client.NewClient(
+			client.WithBaseURL(*yahtzeeURI),
+			client.WithTimeout(*timeout),
+			client.WithRetries(*retries, time.Second))
+	}
 
 	glog.Info("Initializing webcam detector")
 	detector, err := detector.NewYahtzeeDetector(*dev, *imageProcessingURI, *imageDir, *annotate)
@@ -56,8 +83,8 @@ func main() {
 	var result string
 	for result != "q" {
 		glog.Info("Playing game")
-		player := rpi.NewYahtzeePlayer(detector, client, controller)
-		if err = player.Play(*scoreToBeat); err != nil {
+		player := rpi.NewYahtzeePlayer(detector, advisor, controller)
+		if err = player.Play(context.Background(), *scoreToBeat); err != nil {
 			glog.Error(err)
 		}
 
@@ -0,0 +1,229 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+
+	"github.com/timpalpant/yahtzee"
+	"github.com/timpalpant/yahtzee/optimization"
+)
+
+// deviation is the difference between two tables for one GameState.
+type deviation struct {
+	game      yahtzee.GameState
+	a, b      optimization.GameResult
+	deviation float64
+}
+
+// difference returns the largest absolute difference between a and b.
+// Score distributions and expected work are compared at every score.
+// A NaN in either table is an infinite difference.
+func difference(a, b optimization.GameResult) float64 {
+	switch a := a.(type) {
+	case optimization.ExpectedValue:
+		return absDifference(float32(a), float32(b.(optimization.ExpectedValue)))
+	case optimization.ScoreDistribution:
+		return maxAbsDifference(a, b.(optimization.ScoreDistribution))
+	case optimization.ExpectedWork:
+		return maxAbsDifference(a.Values, b.(optimization.ExpectedWork).Values)
+	}
+
+	panic(fmt.Errorf("unknown observable: %T", a))
+}
+
+func maxAbsDifference(a, b []float32) float64 {
+	var result float64
+	for i := range a {
+		if d := absDifference(a[i], b[i]); d > result {
+			result = d
+		}
+	}
+
+	return result
+}
+
+func absDifference(a, b float32) float64 {
+	d := math.Abs(float64(a) - float64(b))
+	if math.IsNaN(d) {
+		return math.Inf(1)
+	}
+
+	return d
+}
+
+// tolerance is the difference allowed between two values: up to abs,
+// or up to rel times the larger of the values. The relative tolerance
+// allows for float32 rounding of large values (e.g. expected work).
+type tolerance struct {
+	abs, rel float64
+}
+
+func (tol tolerance) String() string {
+	return fmt.Sprintf("%g (or %g relative)", tol.abs, tol.rel)
+}
+
+// allows returns true if a and b are within the tolerance at every score.
+func (tol tolerance) allows(a, b optimization.GameResult) bool {
+	switch a := a.(type) {
+	case optimization.ExpectedValue:
+		return tol.allowsValues([]float32{float32(a)}, []float32{float32(b.(optimization.ExpectedValue))})
+	case optimization.ScoreDistribution:
+		return tol.allowsValues(a, b.(optimization.ScoreDistribution))
+	case optimization.ExpectedWork:
+		return tol.allowsValues(a.Values, b.(optimization.ExpectedWork).Values)
+	}
+
+	panic(fmt.Errorf("unknown observable: %T", a))
+}
+
+func (tol tolerance) allowsValues(a, b []float32) bool {
+	for i := range a {
+		d := absDifference(a[i], b[i])
+		scale := math.Max(math.Abs(float64(a[i])), math.Abs(float64(b[i])))
+		if d > tol.abs && !(d <= tol.rel*scale) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// table is a strategy table that can be compared.
+type table interface {
+	Lookup(game yahtzee.GameState) (optimization.GameResult, bool)
+}
+
+// tableDiff is the result of comparing two tables.
+type tableDiff struct {
+	compared   int
+	sum, max   float64
+	deviations []deviation
+	// onlyA and onlyB are the GameStates in only one of the tables.
+	onlyA, onlyB []yahtzee.GameState
+}
+
+// diffTables compares the value of each of games in tables a and b.
+func diffTables(a, b table, games []yahtzee.GameState) *tableDiff {
+	result := &tableDiff{}
+	for _, game := range games {
+		resultA, okA := a.Lookup(game)
+		resultB, okB := b.Lookup(game)
+		switch {
+		case okA && okB:
+			d := difference(resultA, resultB)
+			result.compared++
+			result.sum += d
+			if d > result.max {
+				result.max = d
+			}
+			if d > 0 {
+				result.deviations = append(result.deviations, deviation{game, resultA, resultB, d})
+			}
+		case okA:
+			result.onlyA = append(result.onlyA, game)
+		case okB:
+			result.onlyB = append(result.onlyB, game)
+		}
+	}
+
+	return result
+}
+
+func runDiff(flags *flag.FlagSet, observable *string, args []string) int {
+	absTolerance := flags.Float64("tolerance", 1e-4,
+		"Maximum absolute difference allowed between the tables")
+	relTolerance := flags.Float64("rel_tolerance", 1e-6,
+		"Maximum difference allowed between the tables, relative to the larger value")
+	top := flags.Int("top", 10, "Number of GameStates with the largest differences to show")
+	allowMissing := flags.Bool("allow_missing", false,
+		"Do not fail if a GameState is in only one of the tables")
+	args = parseArgs(flags, args, 2, 2)
+
+	a, err := loadTable(*observable, args[0])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	b, err := loadTable(*observable, args[1])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	diff := diffTables(a, b, validGames())
+	tol := tolerance{abs: *absTolerance, rel: *relTolerance}
+	return diff.report(os.Stdout, args[0], args[1], *top, tol, *allowMissing)
+}
+
+// report writes the differences between the tables in nameA and nameB
+// to w, showing the top largest, and returns the exit code.
+func (diff *tableDiff) report(w io.Writer, nameA, nameB string, top int, tol tolerance, allowMissing bool) int {
+	var mean float64
+	if diff.compared > 0 {
+		mean = diff.sum / float64(diff.compared)
+	}
+
+	fmt.Fprintf(w, "Compared %d GameStates\n", diff.compared)
+	fmt.Fprintf(w, "Max absolute difference: %.6g\n", diff.max)
+	fmt.Fprintf(w, "Mean absolute difference: %.6g\n", mean)
+	fmt.Fprintf(w, "GameStates that differ: %d\n", len(diff.deviations))
+
+	deviations := diff.deviations
+	sort.Slice(deviations, func(i, j int) bool {
+		return deviations[i].deviation > deviations[j].deviation
+	})
+	if len(deviations) > top {
+		deviations = deviations[:top]
+	}
+	if len(deviations) > 0 {
+		fmt.Fprintln(w, "Largest differences:")
+	}
+	for _, d := range deviations {
+		fmt.Fprintf(w, "  %.6g  %v\n", d.deviation, d.game)
+		fmt.Fprintf(w, "    %v: %v\n", nameA, describe(d.a))
+		fmt.Fprintf(w, "    %v: %v\n", nameB, describe(d.b))
+	}
+
+	printMissing(w, nameB, diff.onlyA, top)
+	printMissing(w, nameA, diff.onlyB, top)
+
+	exceeded := 0
+	for _, d := range diff.deviations {
+		if !tol.allows(d.a, d.b) {
+			exceeded++
+		}
+	}
+
+	exitCode := 0
+	if exceeded > 0 {
+		fmt.Fprintf(w, "FAIL: %d GameStates differ by more than the tolerance of %v\n", exceeded, tol)
+		exitCode = 1
+	}
+	if !allowMissing && (len(diff.onlyA) > 0 || len(diff.onlyB) > 0) {
+		fmt.Fprintf(w, "FAIL: %d GameStates are missing from one of the tables\n",
+			len(diff.onlyA)+len(diff.onlyB))
+		exitCode = 1
+	}
+
+	return exitCode
+}
+
+// printMissing lists up to max of the GameStates missing from filename.
+func printMissing(w io.Writer, filename string, missing []yahtzee.GameState, max int) {
+	if len(missing) == 0 {
+		return
+	}
+
+	fmt.Fprintf(w, "Missing from %v: %d GameStates\n", filename, len(missing))
+	for i, game := range missing {
+		if i >= max {
+			fmt.Fprintf(w, "  ... %d more\n", len(missing)-max)
+			break
+		}
+		fmt.Fprintf(w, "  %v\n", game)
+	}
+}
@@ -0,0 +1,222 @@
+package main
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/timpalpant/yahtzee"
+	"github.com/timpalpant/yahtzee/optimization"
+)
+
+// mapTable is a table of the GameStates in a map.
+type mapTable map[yahtzee.GameState]optimization.GameResult
+
+func (t mapTable) Lookup(game yahtzee.GameState) (optimization.GameResult, bool) {
+	result, ok := t[game]
+	return result, ok
+}
+
+func TestDifference(t *testing.T) {
+	if d := difference(optimization.ExpectedValue(10), optimization.ExpectedValue(12.5)); d != 2.5 {
+		t.Errorf("difference() = %v, expected 2.5", d)
+	}
+
+	a := optimization.NewScoreDistribution()
+	b := optimization.NewScoreDistribution()
+	a[10], b[10] = 0.5, 0.25
+	a[20], b[20] = 0.1, 0.2
+	if d := difference(a, b); d != 0.25 {
+		t.Errorf("difference() = %v, expected 0.25", d)
+	}
+	if d := difference(a, a); d != 0 {
+		t.Errorf("difference() = %v, expected 0", d)
+	}
+}
+
+func TestDifferenceNaN(t *testing.T) {
+	nan := float32(math.NaN())
+	if d := difference(optimization.ExpectedValue(10), optimization.ExpectedValue(nan)); !math.IsInf(d, 1) {
+		t.Errorf("difference() = %v, expected +Inf", d)
+	}
+
+	a := optimization.NewScoreDistribution()
+	b := optimization.NewScoreDistribution()
+	a[20] = nan
+	if d := difference(a, b); !math.IsInf(d, 1) {
+		t.Errorf("difference() = %v, expected +Inf", d)
+	}
+	if d := difference(b, a); !math.IsInf(d, 1) {
+		t.Errorf("difference() = %v, expected +Inf", d)
+	}
+}
+
+var (
+	gameA = yahtzee.NewGame().SetBoxFilled(yahtzee.Ones)
+	gameB = yahtzee.NewGame().SetBoxFilled(yahtzee.Twos)
+	gameC = yahtzee.NewGame().SetBoxFilled(yahtzee.Threes)
+	gameD = yahtzee.NewGame().SetBoxFilled(yahtzee.Fours)
+)
+
+func TestDiffTables(t *testing.T) {
+	a := mapTable{
+		gameA: optimization.ExpectedValue(200),
+		gameB: optimization.ExpectedValue(210),
+		gameC: optimization.ExpectedValue(220),
+	}
+	b := mapTable{
+		gameA: optimization.ExpectedValue(200),
+		gameB: optimization.ExpectedValue(211),
+		gameD: optimization.ExpectedValue(230),
+	}
+
+	diff := diffTables(a, b, []yahtzee.GameState{gameA, gameB, gameC, gameD})
+	if diff.compared != 2 {
+		t.Errorf("compared = %d, expected 2", diff.compared)
+	}
+	if diff.max != 1 {
+		t.Errorf("max = %v, expected 1", diff.max)
+	}
+	if len(diff.deviations) != 1 || diff.deviations[0].game != gameB {
+		t.Errorf("deviations = %v, expected only %v", diff.deviations, gameB)
+	}
+	if len(diff.onlyA) != 1 || diff.onlyA[0] != gameC {
+		t.Errorf("onlyA = %v, expected [%v]", diff.onlyA, gameC)
+	}
+	if len(diff.onlyB) != 1 || diff.onlyB[0] != gameD {
+		t.Errorf("onlyB = %v, expected [%v]", diff.onlyB, gameD)
+	}
+}
+
+func TestDiffReport(t *testing.T) {
+	games := []yahtzee.GameState{gameA, gameB, gameC, gameD}
+	a := mapTable{
+		gameA: optimization.ExpectedValue(200),
+		gameB: optimization.ExpectedValue(210),
+		gameC: optimization.ExpectedValue(220),
+		gameD: optimization.ExpectedValue(230),
+	}
+	b := mapTable{
+		gameA: optimization.ExpectedValue(200.5),
+		gameB: optimization.ExpectedValue(212),
+		gameC: optimization.ExpectedValue(220.25),
+		gameD: optimization.ExpectedValue(230),
+	}
+
+	var buf bytes.Buffer
+	diff := diffTables(a, b, games)
+	if exitCode := diff.report(&buf, "a", "b", 2, tolerance{abs: 0.1}, false); exitCode != 1 {
+		t.Errorf("exit code = %d, expected 1", exitCode)
+	}
+
+	// The top deviations are listed, largest first.
+	output := buf.String()
+	iB := strings.Index(output, "  2  "+gameB.String())
+	iA := strings.Index(output, "  0.5  "+gameA.String())
+	if iB < 0 || iA < 0 || iB > iA {
+		t.Errorf("expected %v and then %v in largest differences:\n%v", gameB, gameA, output)
+	}
+	if strings.Contains(output, gameC.String()) {
+		t.Errorf("expected only the top 2 differences:\n%v", output)
+	}
+	if !strings.Contains(output, "FAIL: 3 GameStates differ by more than the tolerance of 0.1") {
+		t.Errorf("expected tolerance failure:\n%v", output)
+	}
+
+	buf.Reset()
+	if exitCode := diff.report(&buf, "a", "b", 2, tolerance{abs: 2}, false); exitCode != 0 {
+		t.Errorf("exit code = %d, expected 0 within tolerance:\n%v", exitCode, buf.String())
+	}
+}
+
+func TestTolerance(t *testing.T) {
+	tol := tolerance{abs: 1e-4, rel: 1e-6}
+	nan := float32(math.NaN())
+	testCases := []struct {
+		a, b     float32
+		expected bool
+	}{
+		{200, 200.00005, true},
+		{200, 200.001, false},
+		// Large values are compared relative to their size.
+		{2e6, 2e6 + 0.5, true},
+		{2e6, 2e6 + 4, false},
+		{0, 1e-5, true},
+		{200, nan, false},
+		{nan, nan, false},
+	}
+
+	for _, tc := range testCases {
+		a, b := optimization.ExpectedValue(tc.a), optimization.ExpectedValue(tc.b)
+		if result := tol.allows(a, b); result != tc.expected {
+			t.Errorf("allows(%v, %v) = %v, expected %v", tc.a, tc.b, result, tc.expected)
+		}
+	}
+
+	// Expected work is compared at every score.
+	a := optimization.NewExpectedWork(0)
+	b := optimization.NewExpectedWork(0)
+	a.Values[100], b.Values[100] = 1e6, 1e6+0.5
+	if !tol.allows(a, b) {
+		t.Errorf("allows() = false, expected a relative difference of 5e-7 to be allowed")
+	}
+	b.Values[200] = 1
+	if tol.allows(a, b) {
+		t.Errorf("allows() = true, expected a difference of 1 to fail")
+	}
+}
+
+func TestDiffReportRelative(t *testing.T) {
+	a := mapTable{gameA: optimization.ExpectedValue(3e6)}
+	b := mapTable{gameA: optimization.ExpectedValue(3e6 + 1)}
+
+	var buf bytes.Buffer
+	diff := diffTables(a, b, []yahtzee.GameState{gameA})
+	if exitCode := diff.report(&buf, "a", "b", 10, tolerance{abs: 1e-4}, false); exitCode != 1 {
+		t.Errorf("exit code = %d, expected 1 with only an absolute tolerance", exitCode)
+	}
+
+	buf.Reset()
+	if exitCode := diff.report(&buf, "a", "b", 10, tolerance{abs: 1e-4, rel: 1e-6}, false); exitCode != 0 {
+		t.Errorf("exit code = %d, expected 0 within the relative tolerance:\n%v", exitCode, buf.String())
+	}
+}
+
+func TestDiffReportMissing(t *testing.T) {
+	a := mapTable{
+		gameA: optimization.ExpectedValue(200),
+		gameB: optimization.ExpectedValue(210),
+	}
+	b := mapTable{
+		gameA: optimization.ExpectedValue(200),
+	}
+
+	var buf bytes.Buffer
+	diff := diffTables(a, b, []yahtzee.GameState{gameA, gameB})
+	if exitCode := diff.report(&buf, "a", "b", 10, tolerance{abs: 0.1}, false); exitCode != 1 {
+		t.Errorf("exit code = %d, expected 1", exitCode)
+	}
+	if output := buf.String(); !strings.Contains(output, "Missing from b: 1 GameStates\n  "+gameB.String()) {
+		t.Errorf("expected %v missing from b:\n%v", gameB, output)
+	}
+
+	buf.Reset()
+	if exitCode := diff.report(&buf, "a", "b", 10, tolerance{abs: 0.1}, true); exitCode != 0 {
+		t.Errorf("exit code = %d, expected 0 with allow_missing:\n%v", exitCode, buf.String())
+	}
+}
+
+func TestDiffReportNaN(t *testing.T) {
+	a := mapTable{gameA: optimization.ExpectedValue(200)}
+	b := mapTable{gameA: optimization.ExpectedValue(float32(math.NaN()))}
+
+	var buf bytes.Buffer
+	diff := diffTables(a, b, []yahtzee.GameState{gameA})
+	if exitCode := diff.report(&buf, "a", "b", 10, tolerance{abs: 0.1}, false); exitCode != 1 {
+		t.Errorf("exit code = %d, expected 1:\n%v", exitCode, buf.String())
+	}
+	if len(diff.deviations) != 1 {
+		t.Errorf("deviations = %v, expected the NaN to be counted", diff.deviations)
+	}
+}
@@ -0,0 +1,275 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/timpalpant/yahtzee"
+	"github.com/timpalpant/yahtzee/optimization"
+)
+
+// field is a named column of an exported row.
+type field struct {
+	name  string
+	value interface{}
+}
+
+// exportRow returns the decoded GameState and the value of the
+// table for one row of an export.
+func exportRow(game yahtzee.GameState, result optimization.GameResult) []field {
+	row := []field{
+		{"game_id", uint(game)},
+		{"game", game.String()},
+		{"turn", game.Turn() + 1},
+	}
+	for box := yahtzee.Ones; box <= yahtzee.Yahtzee; box++ {
+		row = append(row, field{"filled_" + snakeCase(box.String()), game.BoxFilled(box)})
+	}
+	row = append(row,
+		field{"upper_half_score", game.UpperHalfScore()},
+		field{"bonus_eligible", game.BonusEligible()})
+
+	switch r := result.(type) {
+	case optimization.ExpectedValue:
+		row = append(row, field{"expected_value", float32(r)})
+	case optimization.ScoreDistribution:
+		row = append(row,
+			field{"mean", r.Mean()},
+			field{"stddev", r.StdDev()})
+		for i, score := range r.Quantiles(summaryQuantiles) {
+			name := fmt.Sprintf("p%02.0f", 100*summaryQuantiles[i])
+			row = append(row, field{name, score})
+		}
+	case optimization.ExpectedWork:
+		for _, score := range workTargets {
+			row = append(row, field{fmt.Sprintf("work_%d", score), r.GetValue(score)})
+		}
+	}
+
+	return row
+}
+
+// snakeCase converts a box name to a column name, e.g. "FullHouse"
+// to "full_house".
+func snakeCase(s string) string {
+	var buf bytes.Buffer
+	for i, c := range s {
+		if c >= 'A' && c <= 'Z' {
+			if i > 0 {
+				buf.WriteByte('_')
+			}
+			c += 'a' - 'A'
+		}
+		buf.WriteRune(c)
+	}
+
+	return buf.String()
+}
+
+// rowWriter writes exported rows in one of the export formats.
+type rowWriter interface {
+	Write(row []field) error
+	Flush() error
+}
+
+type csvWriter struct {
+	w           *csv.Writer
+	wroteHeader bool
+}
+
+func (cw *csvWriter) Write(row []field) error {
+	if !cw.wroteHeader {
+		header := make([]string, len(row))
+		for i, f := range row {
+			header[i] = f.name
+		}
+		if err := cw.w.Write(header); err != nil {
+			return err
+		}
+		cw.wroteHeader = true
+	}
+
+	record := make([]string, len(row))
+	for i, f := range row {
+		record[i] = fmt.Sprint(f.value)
+	}
+
+	return cw.w.Write(record)
+}
+
+func (cw *csvWriter) Flush() error {
+	cw.w.Flush()
+	return cw.w.Error()
+}
+
+// jsonLinesWriter writes each row as a JSON object on its own line,
+// with the fields in the same order as the CSV columns.
+type jsonLinesWriter struct {
+	w *bufio.Writer
+}
+
+func (jw *jsonLinesWriter) Write(row []field) error {
+	jw.w.WriteByte('{')
+	for i, f := range row {
+		if i > 0 {
+			jw.w.WriteByte(',')
+		}
+		value, err := json.Marshal(f.value)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(jw.w, "%q:%s", f.name, value)
+	}
+	_, err := jw.w.WriteString("}\n")
+	return err
+}
+
+func (jw *jsonLinesWriter) Flush() error {
+	return jw.w.Flush()
+}
+
+func newRowWriter(format string, w io.Writer) (rowWriter, error) {
+	switch format {
+	case "csv":
+		return &csvWriter{w: csv.NewWriter(w)}, nil
+	case "jsonl":
+		return &jsonLinesWriter{bufio.NewWriter(w)}, nil
+	}
+
+	return nil, fmt.Errorf("unknown format: %v, options: csv, jsonl", format)
+}
+
+// gameFilter selects the GameStates to export.
+type gameFilter struct {
+	turn          int
+	filled        yahtzee.GameState
+	open          yahtzee.GameState
+	bonusEligible string
+}
+
+func (f *gameFilter) match(game yahtzee.GameState) bool {
+	if f.turn > 0 && game.Turn()+1 != f.turn {
+		return false
+	}
+
+	for box := yahtzee.Ones; box <= yahtzee.Yahtzee; box++ {
+		if f.filled.BoxFilled(box) && !game.BoxFilled(box) {
+			return false
+		} else if f.open.BoxFilled(box) && game.BoxFilled(box) {
+			return false
+		}
+	}
+
+	return f.bonusEligible == "" || f.bonusEligible == strconv.FormatBool(game.BonusEligible())
+}
+
+// parseBoxes parses a comma-separated list of boxes, returned as
+// a GameState with those boxes filled.
+func parseBoxes(s string) (yahtzee.GameState, error) {
+	game := yahtzee.NewGame()
+	if s == "" {
+		return game, nil
+	}
+
+	for _, name := range strings.Split(s, ",") {
+		box, err := yahtzee.ParseBox(name)
+		if err != nil {
+			return game, err
+		}
+		game = game.SetBoxFilled(box)
+	}
+
+	return game, nil
+}
+
+func runExport(flags *flag.FlagSet, observable *string, args []string) int {
+	format := flags.String("format", "csv", "Output format (csv, jsonl)")
+	output := flags.String("output", "", "Output filename (default stdout)")
+	turn := flags.Int("turn", 0, "Only export GameStates at the beginning of this turn (1-13)")
+	filled := flags.String("filled", "", "Only export GameStates with these boxes filled, e.g. Ones,Yahtzee")
+	open := flags.String("open", "", "Only export GameStates with these boxes open")
+	bonusEligible := flags.String("bonus_eligible", "",
+		"Only export GameStates that are (true) or are not (false) eligible for the Yahtzee bonus")
+	filename := parseArgs(flags, args, 1, 1)[0]
+
+	filter := &gameFilter{turn: *turn, bonusEligible: *bonusEligible}
+	var err error
+	if filter.filled, err = parseBoxes(*filled); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	} else if filter.open, err = parseBoxes(*open); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	} else if *bonusEligible != "" && *bonusEligible != "true" && *bonusEligible != "false" {
+		fmt.Fprintf(os.Stderr, "invalid -bonus_eligible: %v, options: true, false\n", *bonusEligible)
+		return 2
+	}
+
+	// Check the format and load the table before creating the output,
+	// so that a mistake does not leave behind an empty output file.
+	if _, err := newRowWriter(*format, ioutil.Discard); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	strat, err := loadTable(*observable, filename)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	if *output == "" {
+		err = exportGames(os.Stdout, *format, strat, filter)
+	} else {
+		var f *os.File
+		if f, err = os.Create(*output); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		err = exportGames(f, *format, strat, filter)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	return 0
+}
+
+// exportGames writes a row for each GameState in strat that matches filter.
+func exportGames(out io.Writer, format string, strat *optimization.Strategy, filter *gameFilter) error {
+	w, err := newRowWriter(format, out)
+	if err != nil {
+		return err
+	}
+
+	for _, game := range validGames() {
+		if !filter.match(game) {
+			continue
+		}
+
+		result, ok := strat.Lookup(game)
+		if !ok {
+			continue
+		}
+
+		if err := w.Write(exportRow(game, result)); err != nil {
+			return err
+		}
+	}
+
+	return w.Flush()
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/timpalpant/yahtzee"
+	"github.com/timpalpant/yahtzee/optimization"
+)
+
+func TestExportJSONLines(t *testing.T) {
+	game := yahtzee.NewGame().SetBoxFilled(yahtzee.FullHouse).AddUpperHalfScore(12)
+	var buf bytes.Buffer
+	w, err := newRowWriter("jsonl", &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Write(exportRow(game, optimization.ExpectedValue(200))); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	var row map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &row); err != nil {
+		t.Fatalf("invalid JSON %q: %v", buf.String(), err)
+	}
+
+	expected := map[string]interface{}{
+		"game":              "filled=FullHouse uhs=12",
+		"turn":              2.0,
+		"filled_full_house": true,
+		"filled_chance":     false,
+		"upper_half_score":  12.0,
+		"bonus_eligible":    false,
+		"expected_value":    200.0,
+	}
+	for key, value := range expected {
+		if row[key] != value {
+			t.Errorf("%v = %v, expected %v", key, row[key], value)
+		}
+	}
+}
+
+func TestGameFilter(t *testing.T) {
+	filter := &gameFilter{
+		turn:   2,
+		filled: yahtzee.NewGame().SetBoxFilled(yahtzee.Yahtzee),
+	}
+
+	if !filter.match(yahtzee.NewGame().SetBoxFilled(yahtzee.Yahtzee)) {
+		t.Error("filter should match a game with Yahtzee filled at turn 2")
+	}
+	if filter.match(yahtzee.NewGame().SetBoxFilled(yahtzee.Chance)) {
+		t.Error("filter should not match a game without Yahtzee filled")
+	}
+	if filter.match(yahtzee.NewGame().SetBoxFilled(yahtzee.Yahtzee).SetBoxFilled(yahtzee.Chance)) {
+		t.Error("filter should not match a game at turn 3")
+	}
+}
+
+func TestExportErrorsDoNotCreateOutput(t *testing.T) {
+	dir, err := ioutil.TempDir("", "export")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	output := filepath.Join(dir, "out.csv")
+	missing := filepath.Join(dir, "missing.gob.gz")
+	testCases := []struct {
+		args     []string
+		expected int
+	}{
+		{[]string{"-format", "cvs", "-output", output, missing}, 2},
+		{[]string{"-output", output, missing}, 1},
+	}
+
+	for _, tc := range testCases {
+		flags := flag.NewFlagSet("export", flag.ContinueOnError)
+		observable := "expected_value"
+		if code := runExport(flags, &observable, tc.args); code != tc.expected {
+			t.Errorf("runExport(%v) = %v, expected %v", tc.args, code, tc.expected)
+		}
+		if _, err := os.Stat(output); !os.IsNotExist(err) {
+			t.Errorf("runExport(%v) created %v", tc.args, output)
+		}
+	}
+}
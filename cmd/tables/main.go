@@ -0,0 +1,358 @@
+// tables inspects the strategy tables computed by compute_scores.
+//
+// Usage:
+//
+//	tables [glog flags] <command> [flags] <args>
+//
+// Commands:
+//
+//	stats FILE...          number of entries and E_0 of each table
+//	e0 FILE                value of a new game
+//	lookup FILE GAME...    value of each GameState
+//	summary FILE GAME      full summary of the value of a GameState
+//	check FILE             check that the table contains every GameState
+//	diff FILE_A FILE_B     compare two tables, failing above -tolerance
+//	export FILE            write the table as CSV or JSON Lines
+//
+// GameStates are written as e.g. "filled=Fives,Sixes,Yahtzee uhs=42 bonus"
+// (see yahtzee.ParseGameState), or by their numeric ID.
+// Every command takes -observable, the observable of the tables
+// (expected_value, score_distribution or expected_work).
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+
+	"github.com/timpalpant/yahtzee"
+	"github.com/timpalpant/yahtzee/optimization"
+)
+
+// command is a subcommand of tables. run returns the exit code.
+type command struct {
+	name  string
+	usage string
+	run   func(flags *flag.FlagSet, observable *string, args []string) int
+}
+
+var commands = []command{
+	{"stats", "FILE...", runStats},
+	{"e0", "FILE", runE0},
+	{"lookup", "FILE GAME...", runLookup},
+	{"summary", "FILE GAME", runSummary},
+	{"check", "FILE", runCheck},
+	{"diff", "FILE_A FILE_B", runDiff},
+	{"export", "FILE", runExport},
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage: %v [glog flags] <command> [flags] <args>\n\nCommands:\n", os.Args[0])
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %v %v\n", cmd.name, cmd.usage)
+	}
+}
+
+func main() {
+	flag.Usage = usage
+	flag.Parse()
+	if flag.NArg() == 0 {
+		usage()
+		os.Exit(2)
+	}
+
+	for _, cmd := range commands {
+		if cmd.name != flag.Arg(0) {
+			continue
+		}
+
+		flags := flag.NewFlagSet(cmd.name, flag.ExitOnError)
+		observable := flags.String("observable", "expected_value",
+			"Observable of the tables (expected_value, score_distribution, expected_work)")
+		flags.Usage = func() {
+			fmt.Fprintf(os.Stderr, "Usage: %v %v [flags] %v\n", os.Args[0], cmd.name, cmd.usage)
+			flags.PrintDefaults()
+		}
+		os.Exit(cmd.run(flags, observable, flag.Args()[1:]))
+	}
+
+	fmt.Fprintf(os.Stderr, "Unknown command: %v\n", flag.Arg(0))
+	usage()
+	os.Exit(2)
+}
+
+// newObservable returns the observable of a game that is over.
+func newObservable(name string) (optimization.GameResult, error) {
+	switch name {
+	case "expected_value":
+		return optimization.NewExpectedValue(), nil
+	case "score_distribution":
+		return optimization.NewScoreDistribution(), nil
+	case "expected_work":
+		// The value of a game that is over (E_0) is not stored in
+		// the table, but is only needed for GameStates that are over.
+		return optimization.NewExpectedWork(0), nil
+	}
+
+	return nil, fmt.Errorf("unknown observable: %v, options: expected_value, score_distribution, expected_work", name)
+}
+
+// loadTable loads the strategy table in filename.
+func loadTable(observable, filename string) (*optimization.Strategy, error) {
+	obs, err := newObservable(observable)
+	if err != nil {
+		return nil, err
+	}
+
+	glog.Infof("Loading %v table from %v", observable, filename)
+	start := time.Now()
+	strat := optimization.NewStrategy(obs)
+	if err := strat.LoadCache(filename); err != nil {
+		return nil, fmt.Errorf("error loading %v: %v", filename, err)
+	}
+	glog.Infof("Loaded %v entries in %v", strat.Count(), time.Since(start))
+
+	return strat, nil
+}
+
+// parseArgs parses the flags of a command, and checks that it was
+// given between min and max positional arguments (max < 0 is unlimited).
+func parseArgs(flags *flag.FlagSet, args []string, min, max int) []string {
+	flags.Parse(args)
+	if flags.NArg() < min || (max >= 0 && flags.NArg() > max) {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	return flags.Args()
+}
+
+// validGames returns every GameState that is valid and not over,
+// which is every GameState that a complete table contains.
+func validGames() []yahtzee.GameState {
+	var games []yahtzee.GameState
+	for game := yahtzee.NewGame(); game < yahtzee.MaxGame; game++ {
+		if game.IsValid() && !game.GameOver() {
+			games = append(games, game)
+		}
+	}
+
+	return games
+}
+
+// parseGame parses a GameState given either in the notation of
+// yahtzee.ParseGameState or by its numeric ID.
+func parseGame(s string) (yahtzee.GameState, error) {
+	if id, err := strconv.ParseUint(s, 10, 32); err == nil {
+		game := yahtzee.GameState(id)
+		if game >= yahtzee.MaxGame || !game.IsValid() {
+			return 0, fmt.Errorf("invalid game: %v", id)
+		}
+		return game, nil
+	}
+
+	return yahtzee.ParseGameState(s)
+}
+
+// workTargets are the scores for which the expected work is shown.
+var workTargets = []int{100, 150, 200, 250, 300, 350, 400}
+
+// describe returns a one-line description of a value in a table.
+func describe(result optimization.GameResult) string {
+	switch r := result.(type) {
+	case optimization.ExpectedValue:
+		return fmt.Sprintf("%.4f", float32(r))
+	case optimization.ScoreDistribution:
+		return fmt.Sprintf("mean=%.4f stddev=%.4f median=%d", r.Mean(), r.StdDev(), r.Median())
+	case optimization.ExpectedWork:
+		fields := make([]string, len(workTargets))
+		for i, target := range workTargets {
+			fields[i] = fmt.Sprintf("work(%d)=%.4g", target, r.GetValue(target))
+		}
+		return strings.Join(fields, " ")
+	}
+
+	return fmt.Sprint(result)
+}
+
+func runStats(flags *flag.FlagSet, observable *string, args []string) int {
+	filenames := parseArgs(flags, args, 1, -1)
+	games := validGames()
+	for _, filename := range filenames {
+		strat, err := loadTable(*observable, filename)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+
+		present := 0
+		for _, game := range games {
+			if _, ok := strat.Lookup(game); ok {
+				present++
+			}
+		}
+
+		fmt.Printf("%v:\n", filename)
+		fmt.Printf("  entries: %d\n", strat.Count())
+		fmt.Printf("  GameStates: %d of %d (%.2f%%)\n", present, len(games),
+			100*float64(present)/float64(len(games)))
+		if e0, ok := strat.Lookup(yahtzee.NewGame()); ok {
+			fmt.Printf("  E_0: %v\n", describe(e0))
+		} else {
+			fmt.Printf("  E_0: missing\n")
+		}
+	}
+
+	return 0
+}
+
+func runE0(flags *flag.FlagSet, observable *string, args []string) int {
+	filename := parseArgs(flags, args, 1, 1)[0]
+	strat, err := loadTable(*observable, filename)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	e0, ok := strat.Lookup(yahtzee.NewGame())
+	if !ok {
+		fmt.Fprintf(os.Stderr, "%v does not contain a new game\n", filename)
+		return 1
+	}
+
+	fmt.Println(describe(e0))
+	return 0
+}
+
+func runLookup(flags *flag.FlagSet, observable *string, args []string) int {
+	args = parseArgs(flags, args, 2, -1)
+	games := make([]yahtzee.GameState, len(args)-1)
+	for i, arg := range args[1:] {
+		game, err := parseGame(arg)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 2
+		}
+		games[i] = game
+	}
+
+	strat, err := loadTable(*observable, args[0])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	exitCode := 0
+	for _, game := range games {
+		if result, ok := strat.Lookup(game); !ok {
+			fmt.Printf("%v: missing\n", game)
+			exitCode = 1
+		} else {
+			fmt.Printf("%v: %v\n", game, describe(result))
+		}
+	}
+
+	return exitCode
+}
+
+// summaryQuantiles are the quantiles shown for score distributions.
+var summaryQuantiles = []float32{0.01, 0.05, 0.1, 0.25, 0.5, 0.75, 0.9, 0.95, 0.99}
+
+func runSummary(flags *flag.FlagSet, observable *string, args []string) int {
+	args = parseArgs(flags, args, 2, 2)
+	game, err := parseGame(args[1])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	strat, err := loadTable(*observable, args[0])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	result, ok := strat.Lookup(game)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "%v does not contain %v\n", args[0], game)
+		return 1
+	}
+
+	fmt.Printf("GameState: %v (ID %d)\n", game, uint(game))
+	fmt.Printf("Turns remaining: %d\n", game.TurnsRemaining())
+	switch r := result.(type) {
+	case optimization.ScoreDistribution:
+		fmt.Printf("Mean: %.4f\n", r.Mean())
+		fmt.Printf("StdDev: %.4f\n", r.StdDev())
+		fmt.Println("Quantiles:")
+		for i, score := range r.Quantiles(summaryQuantiles) {
+			fmt.Printf("  %4.0f%%: %d\n", 100*summaryQuantiles[i], score)
+		}
+		fmt.Println("P(score >= s):")
+		for _, score := range workTargets {
+			fmt.Printf("  %4d: %.6f\n", score, r.GetProbability(score))
+		}
+	case optimization.ExpectedWork:
+		fmt.Println("Expected work to score s:")
+		for _, score := range workTargets {
+			fmt.Printf("  %4d: %.6g\n", score, r.GetValue(score))
+		}
+	default:
+		fmt.Printf("Value: %v\n", describe(result))
+	}
+
+	return 0
+}
+
+// maxMissing is the number of missing GameStates listed by check.
+const maxMissing = 20
+
+func runCheck(flags *flag.FlagSet, observable *string, args []string) int {
+	filename := parseArgs(flags, args, 1, 1)[0]
+	strat, err := loadTable(*observable, filename)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	games := validGames()
+	var missing []yahtzee.GameState
+	missingByTurn := make(map[int]int)
+	for _, game := range games {
+		if _, ok := strat.Lookup(game); !ok {
+			missing = append(missing, game)
+			missingByTurn[game.Turn()]++
+		}
+	}
+
+	if len(missing) == 0 {
+		fmt.Printf("%v contains all %d GameStates\n", filename, len(games))
+		return 0
+	}
+
+	fmt.Printf("%v is missing %d of %d GameStates\n", filename, len(missing), len(games))
+	turns := make([]int, 0, len(missingByTurn))
+	for turn := range missingByTurn {
+		turns = append(turns, turn)
+	}
+	sort.Ints(turns)
+	for _, turn := range turns {
+		fmt.Printf("  turn %2d: %d missing\n", turn+1, missingByTurn[turn])
+	}
+
+	for i, game := range missing {
+		if i >= maxMissing {
+			fmt.Printf("  ... %d more\n", len(missing)-maxMissing)
+			break
+		}
+		fmt.Printf("  %v\n", game)
+	}
+
+	return 1
+}
@@ -0,0 +1,258 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/timpalpant/yahtzee"
+	"github.com/timpalpant/yahtzee/client"
+	"github.com/timpalpant/yahtzee/server"
+)
+
+// choice is a hold or fill option, as ranked by the advisor.
+type choice struct {
+	held              []int
+	box               yahtzee.Box
+	isHold            bool
+	expectedScore     float32
+	probabilityToBeat float32
+	server.Explanation
+}
+
+func (c choice) String() string {
+	if !c.isHold {
+		return fmt.Sprintf("fill %v", c.box)
+	} else if len(c.held) == 0 {
+		return "reroll all"
+	}
+
+	return fmt.Sprintf("hold %v", strings.Trim(fmt.Sprint(c.held), "[]"))
+}
+
+// app is an interactive advisor for a game played with real dice:
+// the player enters the dice they roll, and the app shows the best
+// choices and updates the scorecard as they are made.
+type app struct {
+	advisor     client.Advisor
+	scoreToBeat int
+	// maxChoices is the number of ranked choices to show.
+	maxChoices int
+	screen     *screen
+
+	pos yahtzee.Turn
+	// history of previous positions, for undo.
+	history []yahtzee.Turn
+
+	// gameValue is the expected remaining score at the beginning
+	// of a turn.
+	gameValue float32
+	// choices are the ranked choices for the current dice.
+	choices []choice
+	// message is shown to the player after the last command,
+	// e.g. to report an error.
+	message string
+}
+
+func newApp(advisor client.Advisor, scoreToBeat, maxChoices int, screen *screen) *app {
+	return &app{
+		advisor:     advisor,
+		scoreToBeat: scoreToBeat,
+		maxChoices:  maxChoices,
+		screen:      screen,
+		pos:         yahtzee.NewTurn(),
+	}
+}
+
+// run reads commands from r until it is closed or the player quits.
+func (a *app) run(ctx context.Context, r io.Reader) error {
+	a.advise(ctx)
+	scanner := bufio.NewScanner(r)
+	for {
+		a.screen.render(a)
+		if !scanner.Scan() {
+			return scanner.Err()
+		}
+
+		line := strings.TrimSpace(scanner.Text())
+		if line == "q" || line == "quit" {
+			return nil
+		}
+
+		a.message = ""
+		if err := a.handle(line); err != nil {
+			a.message = err.Error()
+			continue
+		}
+
+		a.advise(ctx)
+	}
+}
+
+// handle executes a single command entered by the player.
+func (a *app) handle(line string) error {
+	cmd, arg := line, ""
+	if i := strings.IndexByte(line, ' '); i >= 0 {
+		cmd, arg = line[:i], strings.TrimSpace(line[i+1:])
+	}
+
+	switch cmd {
+	case "":
+		return a.follow()
+	case "r", "roll":
+		return a.roll(arg)
+	case "h", "hold":
+		dice, err := yahtzee.ParseHold(line)
+		if err != nil {
+			return err
+		}
+		return a.move(a.pos.Hold(sorted(dice)))
+	case "f", "fill":
+		box, err := parseBox(arg)
+		if err != nil {
+			return err
+		}
+		return a.fill(box)
+	case "u", "undo":
+		if len(a.history) == 0 {
+			return fmt.Errorf("nothing to undo")
+		}
+		a.pos = a.history[len(a.history)-1]
+		a.history = a.history[:len(a.history)-1]
+		return nil
+	case "n", "new":
+		return a.move(yahtzee.NewTurn(), nil)
+	case "?", "help":
+		a.message = helpText
+		return nil
+	}
+
+	// Dice may be entered without a command.
+	return a.roll(line)
+}
+
+const helpText = `Commands:
+  12345      enter the dice rolled (only the rerolled dice are needed)
+  h 66       hold dice and reroll the others (h alone rerolls all)
+  f <box>    fill a box, by number or name (e.g. "f 12" or "f ch")
+  ENTER      follow the best choice
+  u          undo the last move
+  n          start a new game
+  q          quit`
+
+// move replaces the current position with next, unless err is set.
+func (a *app) move(next yahtzee.Turn, err error) error {
+	if err != nil {
+		return err
+	}
+
+	a.history = append(a.history, a.pos)
+	a.pos = next
+	return nil
+}
+
+func (a *app) roll(arg string) error {
+	dice, err := yahtzee.ParseDice(arg)
+	if err != nil {
+		return err
+	}
+
+	return a.move(a.pos.Roll(withHeld(a.pos, dice)))
+}
+
+func (a *app) fill(box yahtzee.Box) error {
+	next, value, err := a.pos.Fill(box)
+	if err := a.move(next, err); err != nil {
+		return err
+	}
+
+	a.message = fmt.Sprintf("Scored %d in %v", value, box)
+	return nil
+}
+
+// follow makes the best choice for the current dice.
+func (a *app) follow() error {
+	if len(a.choices) == 0 {
+		return fmt.Errorf("no advice available, enter a command (? for help)")
+	}
+
+	best := a.choices[0]
+	if best.isHold {
+		return a.move(a.pos.Hold(best.held))
+	}
+
+	return a.fill(best.box)
+}
+
+// remainingScoreToBeat returns the number of points needed over
+// the rest of the game to beat the score, or 0 if it has been beaten.
+func (a *app) remainingScoreToBeat() int {
+	remaining := a.scoreToBeat - a.pos.Scorecard.Total()
+	if remaining < 0 {
+		return 0
+	}
+
+	return remaining
+}
+
+// advise updates the advice for the current position.
+func (a *app) advise(ctx context.Context) {
+	a.gameValue = 0
+	a.choices = nil
+	if a.pos.Scorecard.Game.GameOver() {
+		return
+	}
+
+	if a.pos.NeedsRoll() {
+		if a.pos.Rolls == 0 {
+			value, err := a.advisor.GetGameValue(ctx, a.pos.Scorecard.Game, 0)
+			if err != nil {
+				a.message = err.Error()
+			}
+			a.gameValue = value
+		}
+		return
+	}
+
+	resp, err := a.advisor.Explain(ctx, &server.ExplainRequest{
+		GameState: server.FromYahtzeeGameState(a.pos.Scorecard.Game),
+		TurnState: server.TurnState{
+			Step: a.pos.Step(),
+			Dice: a.pos.Dice,
+		},
+		ScoreToBeat: a.remainingScoreToBeat(),
+	})
+	if err != nil {
+		a.message = err.Error()
+		return
+	}
+
+	for _, hold := range resp.HoldChoices {
+		a.choices = append(a.choices, choice{
+			held:              hold.HeldDice,
+			isHold:            true,
+			expectedScore:     hold.ExpectedFinalScore,
+			probabilityToBeat: hold.ProbabilityToBeat,
+			Explanation:       hold.Explanation,
+		})
+	}
+	for _, fill := range resp.FillChoices {
+		a.choices = append(a.choices, choice{
+			box:               yahtzee.Box(fill.BoxFilled),
+			expectedScore:     fill.ExpectedFinalScore,
+			probabilityToBeat: fill.ProbabilityToBeat,
+			Explanation:       fill.Explanation,
+		})
+	}
+	// Among equally good choices, filling a box now is simpler
+	// than holding all of the dice to fill it later.
+	sort.SliceStable(a.choices, func(i, j int) bool {
+		if a.choices[i].Rank != a.choices[j].Rank {
+			return a.choices[i].Rank < a.choices[j].Rank
+		}
+		return !a.choices[i].isHold && a.choices[j].isHold
+	})
+}
@@ -0,0 +1,56 @@
+// yahtzee_advisor is a terminal app that advises on a game of Yahtzee
+// played with real dice. It shows the scorecard and ranks the choices
+// for each roll, using either a Yahtzee server or local score tables.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/timpalpant/yahtzee/client"
+	"github.com/timpalpant/yahtzee/server"
+)
+
+func main() {
+	uri := flag.String("uri", "http://localhost:8080", "URI of Yahtzee server")
+	expectedScores := flag.String("expected_scores", "",
+		"File with expected scores to load, instead of using the Yahtzee server")
+	scoreDistributions := flag.String("score_distributions", "",
+		"File with score distributions to load, instead of using the Yahtzee server")
+	expectedWork := flag.String("expected_work", "",
+		"File with expected work distributions to load, instead of using the Yahtzee server")
+	scoreToBeat := flag.Int("score_to_beat", 0,
+		"High score to try to beat (if 0, maximize expected score)")
+	maxChoices := flag.Int("choices", 8, "Number of ranked choices to show")
+	ansi := flag.Bool("ansi", true, "Use ANSI escapes to redraw the screen and highlight choices")
+	flag.Parse()
+
+	var advisor client.Advisor
+	if *expectedScores != "" || *scoreDistributions != "" || *expectedWork != "" {
+		fmt.Println("Loading strategy tables...")
+		local, err := client.NewLocal(map[string]string{
+			server.TableExpectedValue:     *expectedScores,
+			server.TableScoreDistribution: *scoreDistributions,
+			server.TableExpectedWork:      *expectedWork,
+		})
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		advisor = local
+	} else {
+		advisor = client.NewClient(
+			client.WithBaseURL(*uri),
+			client.WithTimeout(time.Minute),
+			client.WithRetries(2, time.Second))
+	}
+
+	a := newApp(advisor, *scoreToBeat, *maxChoices, &screen{os.Stdout, *ansi})
+	if err := a.run(context.Background(), os.Stdin); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/timpalpant/yahtzee"
+)
+
+// withHeld returns the dice of a roll, which may be entered as either
+// all of the dice or only the rerolled (not held) dice, in sorted order.
+func withHeld(t yahtzee.Turn, dice []int) []int {
+	if t.Held != nil && len(dice) == yahtzee.NDice-len(t.Held) {
+		dice = append(append([]int(nil), t.Held...), dice...)
+	}
+
+	return sorted(dice)
+}
+
+func sorted(dice []int) []int {
+	result := append([]int(nil), dice...)
+	sort.Ints(result)
+	return result
+}
+
+// parseBox parses a box by its number on the scorecard (1-13),
+// or by a unique prefix of its name, e.g. "ch" for Chance.
+func parseBox(s string) (yahtzee.Box, error) {
+	if n, err := strconv.Atoi(s); err == nil {
+		if n < 1 || n > yahtzee.NumTurns {
+			return 0, fmt.Errorf("invalid box number: %d", n)
+		}
+		return yahtzee.Box(n - 1), nil
+	}
+
+	if box, err := yahtzee.ParseBox(s); err == nil {
+		return box, nil
+	}
+
+	var matches []yahtzee.Box
+	for box := yahtzee.Ones; box <= yahtzee.Yahtzee; box++ {
+		name := strings.ToLower(box.String())
+		if s != "" && strings.HasPrefix(name, strings.ToLower(s)) {
+			matches = append(matches, box)
+		}
+	}
+
+	if len(matches) != 1 {
+		return 0, fmt.Errorf("unknown box: %q", s)
+	}
+
+	return matches[0], nil
+}
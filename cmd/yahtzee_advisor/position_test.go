@@ -0,0 +1,80 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/timpalpant/yahtzee"
+)
+
+func TestAppTurn(t *testing.T) {
+	a := newApp(nil, 0, 5, nil)
+	if err := a.handle("62631"); err != nil {
+		t.Fatal(err)
+	} else if a.pos.Step() != yahtzee.Hold1 || !reflect.DeepEqual(a.pos.Dice, []int{1, 2, 3, 6, 6}) {
+		t.Errorf("roll = %+v, expected sorted dice at Hold1", a.pos)
+	}
+
+	if err := a.handle("h 666"); err == nil {
+		t.Error("hold succeeded with dice that were not rolled")
+	}
+
+	if err := a.handle("h 66"); err != nil {
+		t.Fatal(err)
+	} else if !a.pos.NeedsRoll() {
+		t.Error("hold should require the dice to be rerolled")
+	}
+
+	// Only the rerolled dice are needed.
+	if err := a.handle("646"); err != nil {
+		t.Fatal(err)
+	} else if a.pos.Step() != yahtzee.Hold2 || !reflect.DeepEqual(a.pos.Dice, []int{4, 6, 6, 6, 6}) {
+		t.Errorf("roll = %+v, expected held dice to be kept at Hold2", a.pos)
+	}
+
+	// Holding all of the dice skips to filling a box.
+	if err := a.handle("h 46666"); err != nil {
+		t.Fatal(err)
+	} else if a.pos.Step() != yahtzee.FillBox || a.pos.NeedsRoll() {
+		t.Errorf("hold = %+v, expected to fill a box with the same dice", a.pos)
+	}
+
+	if err := a.handle("f 6"); err != nil {
+		t.Fatal(err)
+	} else if a.pos.Rolls != 0 || a.pos.Scorecard.BoxScores[yahtzee.Sixes] != 24 {
+		t.Errorf("fill = %+v, expected 24 in Sixes and a new turn", a.pos)
+	} else if a.message != "Scored 24 in Sixes" {
+		t.Errorf("message = %q, expected the points scored", a.message)
+	}
+
+	if err := a.handle("u"); err != nil {
+		t.Fatal(err)
+	} else if a.pos.Step() != yahtzee.FillBox || a.pos.Scorecard.Game.BoxFilled(yahtzee.Sixes) {
+		t.Errorf("undo = %+v, expected to fill a box again", a.pos)
+	}
+}
+
+func TestParseBox(t *testing.T) {
+	testCases := []struct {
+		s       string
+		box     yahtzee.Box
+		wantErr bool
+	}{
+		{"1", yahtzee.Ones, false},
+		{"13", yahtzee.Yahtzee, false},
+		{"14", 0, true},
+		{"ch", yahtzee.Chance, false},
+		{"fullhouse", yahtzee.FullHouse, false},
+		{"f", 0, true},
+		{"", 0, true},
+	}
+
+	for _, tc := range testCases {
+		box, err := parseBox(tc.s)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("parseBox(%q) returned error %v", tc.s, err)
+		} else if !tc.wantErr && box != tc.box {
+			t.Errorf("parseBox(%q) = %v, expected %v", tc.s, box, tc.box)
+		}
+	}
+}
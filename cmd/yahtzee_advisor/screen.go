@@ -0,0 +1,191 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/timpalpant/yahtzee"
+	"github.com/timpalpant/yahtzee/server"
+)
+
+// ANSI escape sequences used to draw the screen.
+const (
+	clearScreen = "\x1b[H\x1b[2J"
+	bold        = "\x1b[1m"
+	dim         = "\x1b[2m"
+	green       = "\x1b[32m"
+	yellow      = "\x1b[33m"
+	reset       = "\x1b[0m"
+)
+
+// screen draws the state of the app to a terminal. If ansi is false,
+// escape sequences are omitted, e.g. when the output is not a terminal.
+type screen struct {
+	w    io.Writer
+	ansi bool
+}
+
+// style wraps s with the given escape sequence.
+func (s *screen) style(esc, str string) string {
+	if !s.ansi {
+		return str
+	}
+
+	return esc + str + reset
+}
+
+// render redraws the screen: the scorecard, the current dice,
+// the ranked choices, and the prompt for the next command.
+func (s *screen) render(a *app) {
+	var buf bytes.Buffer
+	if s.ansi {
+		buf.WriteString(clearScreen)
+	}
+
+	sc := a.pos.Scorecard
+	game := sc.Game
+	header := "YAHTZEE ADVISOR"
+	if a.scoreToBeat > 0 {
+		header += fmt.Sprintf(" - score to beat: %d", a.scoreToBeat)
+	}
+	fmt.Fprintf(&buf, "%v\n\n", s.style(bold, header))
+
+	s.renderScorecard(&buf, sc)
+	buf.WriteString("\n")
+
+	switch {
+	case game.GameOver():
+		result := fmt.Sprintf("Game over! Final score: %d", sc.Total())
+		if a.scoreToBeat > 0 && sc.Total() > a.scoreToBeat {
+			result += fmt.Sprintf(" (beat %d)", a.scoreToBeat)
+		}
+		fmt.Fprintf(&buf, "%v\n", s.style(bold, result))
+	case a.pos.NeedsRoll():
+		fmt.Fprintf(&buf, "Turn %d of %d, roll %d of 3\n", game.Turn()+1, yahtzee.NumTurns, a.pos.Rolls+1)
+		if len(a.pos.Held) > 0 {
+			fmt.Fprintf(&buf, "Held: %v\n", s.dice(a.pos.Held))
+			fmt.Fprintf(&buf, "Enter the %d rerolled dice.\n", yahtzee.NDice-len(a.pos.Held))
+		} else {
+			if a.pos.Rolls == 0 && a.gameValue > 0 {
+				fmt.Fprintf(&buf, "Expected final score: %.1f\n", float32(sc.Total())+a.gameValue)
+			}
+			buf.WriteString("Enter the dice rolled.\n")
+		}
+	default:
+		fmt.Fprintf(&buf, "Turn %d of %d, roll %d of 3\n", game.Turn()+1, yahtzee.NumTurns, a.pos.Rolls)
+		fmt.Fprintf(&buf, "Dice: %v\n\n", s.dice(a.pos.Dice))
+		s.renderChoices(&buf, a)
+	}
+
+	if a.message != "" {
+		fmt.Fprintf(&buf, "\n%v\n", a.message)
+	}
+	buf.WriteString("\n> ")
+
+	s.w.Write(buf.Bytes())
+}
+
+func (s *screen) renderScorecard(buf *bytes.Buffer, sc yahtzee.Scorecard) {
+	box := func(b yahtzee.Box) string {
+		score := s.style(dim, "   -")
+		if sc.Game.BoxFilled(b) {
+			score = fmt.Sprintf("%4d", sc.BoxScores[b])
+		}
+		return fmt.Sprintf("%2d %-14v%v", b+1, b, score)
+	}
+
+	for i := 0; i < yahtzee.NumTurns-int(yahtzee.ThreeOfAKind); i++ {
+		left := strings.Repeat(" ", 21)
+		if b := yahtzee.Box(i); b <= yahtzee.Sixes {
+			left = box(b)
+		}
+		fmt.Fprintf(buf, "  %v    %v\n", left, box(yahtzee.ThreeOfAKind+yahtzee.Box(i)))
+	}
+
+	upper := sc.UpperHalfScore()
+	var bonus string
+	switch {
+	case sc.UpperHalfBonus > 0:
+		bonus = s.style(green, fmt.Sprintf("bonus +%d", sc.UpperHalfBonus))
+	case upperHalfFilled(sc.Game):
+		bonus = s.style(dim, "no bonus")
+	default:
+		bonus = fmt.Sprintf("%d to bonus", yahtzee.UpperHalfBonusThreshold-upper)
+	}
+	fmt.Fprintf(buf, "\n  Upper %3d/%d %v %v\n", upper, yahtzee.UpperHalfBonusThreshold, progressBar(upper), bonus)
+	if sc.YahtzeeBonus > 0 {
+		fmt.Fprintf(buf, "  Yahtzee bonus %d\n", sc.YahtzeeBonus)
+	}
+	fmt.Fprintf(buf, "  %v\n", s.style(bold, fmt.Sprintf("Total %d", sc.Total())))
+}
+
+func upperHalfFilled(game yahtzee.GameState) bool {
+	for b := yahtzee.Ones; b <= yahtzee.Sixes; b++ {
+		if !game.BoxFilled(b) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// progressBar shows the progress of the upper half score
+// toward the bonus threshold.
+func progressBar(upper int) string {
+	const width = 21
+	n := width * upper / yahtzee.UpperHalfBonusThreshold
+	if n > width {
+		n = width
+	}
+
+	return "[" + strings.Repeat("#", n) + strings.Repeat(".", width-n) + "]"
+}
+
+func (s *screen) dice(dice []int) string {
+	strs := make([]string, len(dice))
+	for i, die := range dice {
+		strs[i] = s.style(bold, fmt.Sprintf("[%d]", die))
+	}
+
+	return strings.Join(strs, " ")
+}
+
+func (s *screen) renderChoices(buf *bytes.Buffer, a *app) {
+	if len(a.choices) == 0 {
+		return
+	}
+
+	withProbability := a.remainingScoreToBeat() > 0
+	total := float32(a.pos.Scorecard.Total())
+	fmt.Fprintf(buf, "  %-4v %-20v %10v", "Rank", "Choice", "Exp. score")
+	if withProbability {
+		fmt.Fprintf(buf, " %8v", "P(beat)")
+	}
+	buf.WriteString("\n")
+
+	for i, c := range a.choices {
+		if i >= a.maxChoices {
+			break
+		}
+
+		line := fmt.Sprintf("%-4d %-20v %10.1f", c.Rank, c, total+c.expectedScore)
+		if withProbability {
+			line += fmt.Sprintf(" %7.1f%%", 100*c.probabilityToBeat)
+		}
+		line += "  " + c.Description
+		switch {
+		case c.Rank == 1:
+			line = s.style(green, line)
+		case c.Category == server.CategoryScratch:
+			line = s.style(yellow, line)
+		}
+		fmt.Fprintf(buf, "  %v\n", line)
+	}
+
+	if len(a.choices) > a.maxChoices {
+		fmt.Fprintf(buf, "  %v\n", s.style(dim, fmt.Sprintf("... %d more", len(a.choices)-a.maxChoices)))
+	}
+	fmt.Fprintf(buf, "\nPress ENTER to %v.\n", a.choices[0])
+}
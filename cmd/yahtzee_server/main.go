@@ -4,12 +4,14 @@ import (
 	"flag"
 	"fmt"
 	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
 
 	"github.com/NYTimes/gziphandler"
 	"github.com/golang/glog"
 
-	"github.com/timpalpant/yahtzee"
-	"github.com/timpalpant/yahtzee/optimization"
 	"github.com/timpalpant/yahtzee/server"
 )
 
@@ -23,49 +25,100 @@ func main() {
 	expectedWork := flag.String(
 		"expected_work", "../../data/expected-work.gob.gz",
 		"File with expected work distributions to load")
+	sessionTTL := flag.Duration("session_ttl", server.DefaultSessionTTL,
+		"Time after which idle game sessions expire")
+	resultCacheMB := flag.Int64("result_cache_mb", server.DefaultResultCacheBytes>>20,
+		"Maximum memory (in MB) used by cached turn outcomes (0 to disable)")
+	assetsDir := flag.String("assets_dir", "",
+		"Directory with templates/ and static/ to serve the web UI from, "+
+			"instead of the copies embedded in the binary")
+	enableAdmin := flag.Bool("enable_admin", false,
+		"Enable the /admin/reload endpoint to reload tables")
+	tableDir := flag.String("table_dir", "",
+		"Directory that /admin/reload may load tables from "+
+			"(if not set, tables are only reloaded from their current files)")
+	allowedOrigins := flag.String("allowed_origins", "",
+		"Comma-separated origins of other sites that may connect to "+
+			"/rest/v1/live, e.g. https://example.com")
 	port := flag.Int("port", 8080, "Port to bind to")
 	flag.Parse()
 
-	glog.Info("Loading expected scores table")
-	expectedScoreStrat := optimization.NewStrategy(optimization.NewExpectedValue())
-	err := expectedScoreStrat.LoadCache(*expectedScores)
-	if err != nil {
-		glog.Fatal(err)
+	tables := map[string]string{
+		server.TableExpectedValue:     *expectedScores,
+		server.TableScoreDistribution: *scoreDistributions,
+		server.TableExpectedWork:      *expectedWork,
 	}
 
-	glog.Info("Loading score distributions table")
-	highScoreStrat := optimization.NewStrategy(optimization.NewScoreDistribution())
-	err = highScoreStrat.LoadCache(*scoreDistributions)
-	if err != nil {
-		glog.Fatal(err)
+	glog.Info("Starting server")
+	ys := server.NewYahtzeeServer(nil, nil, nil)
+	// Tables are loaded in the background while the server is running.
+	// If a table is not provided (or fails to load), the server runs
+	// without it, and requests that need it fail with 503.
+	for name, filename := range tables {
+		if filename != "" {
+			ys.LoadTableInBackground(name, filename)
+		}
 	}
 
-	glog.Info("Loading expected work table")
-	expectedWorkStrat := optimization.NewStrategy(optimization.NewExpectedWork(0))
-	err = expectedWorkStrat.LoadCache(*expectedWork)
-	if err != nil {
-		glog.Fatal(err)
+	ys.SetSessionTTL(*sessionTTL)
+	ys.SetTableDir(*tableDir)
+	if *allowedOrigins != "" {
+		ys.SetAllowedOrigins(strings.Split(*allowedOrigins, ","))
+	}
+	ys.SetResultCacheBytes(*resultCacheMB << 20)
+	if *assetsDir != "" {
+		if err := ys.SetAssetsDir(*assetsDir); err != nil {
+			glog.Fatal(err)
+		}
 	}
 
-	glog.Info("Reloading expected work table with initialized E_0")
-	e0 := expectedWorkStrat.Compute(yahtzee.NewGame())
-	expectedWorkStrat = optimization.NewStrategy(e0)
-	err = expectedWorkStrat.LoadCache(*expectedWork)
-	if err != nil {
-		glog.Fatal(err)
+	// Reload tables from the same files on SIGHUP, e.g. after they
+	// have been rebuilt with compute_scores.
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	go func() {
+		for range hup {
+			glog.Info("Received SIGHUP, reloading tables")
+			for _, result := range ys.Reload(&server.ReloadRequest{}).Tables {
+				if result.Error != nil {
+					glog.Errorf("Failed to reload %v table: %v", result.Name, result.Error)
+				}
+			}
+		}
+	}()
+
+	// handle registers the handler for pattern, and records
+	// request metrics for it under the given endpoint name.
+	handle := func(pattern, endpoint string, h http.Handler) {
+		http.Handle(pattern, server.Instrument(endpoint, h))
 	}
 
-	glog.Info("Starting server")
-	server := server.NewYahtzeeServer(highScoreStrat, expectedScoreStrat, expectedWorkStrat)
-	http.Handle("/",
-		gziphandler.GzipHandler(http.HandlerFunc(server.Index)))
-	http.Handle("/rest/v1/score",
-		gziphandler.GzipHandler(http.HandlerFunc(server.GetScore)))
-	http.Handle("/rest/v1/optimal_move",
-		gziphandler.GzipHandler(http.HandlerFunc(server.OptimalMove)))
-	http.Handle("/rest/v1/outcome_distribution",
-		gziphandler.GzipHandler(http.HandlerFunc(server.OutcomeDistribution)))
-	http.Handle("/static/", gziphandler.GzipHandler(
-		http.StripPrefix("/static/", http.FileServer(http.Dir("static")))))
+	handle("/", "index",
+		gziphandler.GzipHandler(http.HandlerFunc(ys.Index)))
+	handle("/rest/v1/score", "score",
+		gziphandler.GzipHandler(http.HandlerFunc(ys.GetScore)))
+	handle("/rest/v1/optimal_move", "optimal_move",
+		gziphandler.GzipHandler(http.HandlerFunc(ys.OptimalMove)))
+	handle("/rest/v1/outcome_distribution", "outcome_distribution",
+		gziphandler.GzipHandler(http.HandlerFunc(ys.OutcomeDistribution)))
+	handle("/rest/v1/explain", "explain",
+		gziphandler.GzipHandler(http.HandlerFunc(ys.Explain)))
+	handle("/rest/v1/batch/optimal_move", "batch_optimal_move",
+		gziphandler.GzipHandler(http.HandlerFunc(ys.BatchOptimalMove)))
+	handle("/rest/v1/games", "games",
+		gziphandler.GzipHandler(http.HandlerFunc(ys.Games)))
+	handle("/rest/v1/games/", "games",
+		gziphandler.GzipHandler(http.HandlerFunc(ys.Games)))
+	handle("/rest/v1/live", "live", http.HandlerFunc(ys.LiveAdvice))
+	handle("/debug/game_state", "debug_game_state", http.HandlerFunc(ys.DebugGameState))
+	handle("/openapi.json", "openapi", http.HandlerFunc(ys.OpenAPI))
+	handle("/healthz", "healthz", http.HandlerFunc(ys.Healthz))
+	handle("/readyz", "readyz", http.HandlerFunc(ys.Readyz))
+	if *enableAdmin {
+		handle("/admin/reload", "admin_reload", http.HandlerFunc(ys.ReloadTables))
+	}
+	handle("/static/", "static",
+		gziphandler.GzipHandler(http.HandlerFunc(ys.Static)))
+	http.HandleFunc("/metrics", ys.Metrics)
 	glog.Fatal(http.ListenAndServe(fmt.Sprintf(":%d", *port), nil))
 }
@@ -163,11 +163,6 @@ func (game GameState) FillBox(box Box, roll Roll) (GameState, int) {
 	return newGame, value
 }
 
-func (game GameState) String() string {
-	return fmt.Sprintf("{ID: %d, Available: %v, BonusEligible: %v, UpperHalf: %v}",
-		game, game.AvailableBoxes(), game.BonusEligible(), game.UpperHalfScore())
-}
-
 func nativeUpperHalfBox(yahtzeeRoll Roll) Box {
 	side := yahtzeeRoll.One()
 	return Box(side - 1)
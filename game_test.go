@@ -21,6 +21,12 @@ func TestGameOver(t *testing.T) {
 		{GameState(6000000), false},
 		{GameState(6000000 | boxesMask), true},
 		{GameState(6000000 | boxesMask&^(1<<Twos)), false},
+		{mustParseGameState(allFilled), true},
+		{mustParseGameState("filled=Threes,Fours,FullHouse,SmallStraight,LargeStraight,Chance,Yahtzee uhs=3 bonus"), false},
+		{mustParseGameState("filled=Fours,Fives,Sixes,FullHouse uhs=63"), false},
+		{mustParseGameState(allFilled + " uhs=63 bonus"), true},
+		{mustParseGameState("filled=Ones,Threes,Fours,Fives,Sixes,ThreeOfAKind,FourOfAKind," +
+			"FullHouse,SmallStraight,LargeStraight,Chance,Yahtzee uhs=63 bonus"), false},
 	}
 
 	for _, tc := range cases {
@@ -0,0 +1,185 @@
+package yahtzee
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// GameStates are written in a compact notation of space-separated
+// fields: the filled boxes, the upper half score, and whether the
+// player is eligible for the Yahtzee bonus. For example:
+//
+//   filled=Fives,Sixes,Yahtzee uhs=42 bonus
+//
+// Fields that are empty (no boxes filled, an upper half score of 0,
+// or no bonus) are omitted, and a new game is written as "new".
+
+// ParseGameState parses a GameState written in the notation
+// produced by GameState.String. Each field may appear at most once,
+// and the GameState must be valid: the upper half score must be
+// possible with the filled upper boxes, and the Yahtzee bonus requires
+// a filled Yahtzee.
+func ParseGameState(s string) (GameState, error) {
+	if s == "new" {
+		return NewGame(), nil
+	}
+
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		return 0, fmt.Errorf("empty game state")
+	}
+
+	game := NewGame()
+	seen := make(map[string]bool)
+	for _, field := range fields {
+		key, value := field, ""
+		if i := strings.IndexByte(field, '='); i >= 0 {
+			key, value = field[:i], field[i+1:]
+		}
+
+		if seen[key] {
+			return 0, fmt.Errorf("duplicate field: %q", key)
+		}
+		seen[key] = true
+
+		switch key {
+		case "filled":
+			for _, name := range strings.Split(value, ",") {
+				box, err := ParseBox(name)
+				if err != nil {
+					return 0, err
+				} else if game.BoxFilled(box) {
+					return 0, fmt.Errorf("duplicate box: %v", box)
+				}
+				game = game.SetBoxFilled(box)
+			}
+		case "uhs":
+			uhs, err := strconv.Atoi(value)
+			if err != nil || uhs < 0 || uhs > UpperHalfBonusThreshold {
+				return 0, fmt.Errorf("invalid upper half score: %q, must be 0-%d",
+					value, UpperHalfBonusThreshold)
+			}
+			game = game.AddUpperHalfScore(uhs)
+		case "bonus":
+			if value != "" || strings.ContainsRune(field, '=') {
+				return 0, fmt.Errorf("bonus does not take a value: %q", field)
+			}
+			game = game.SetBonusEligible()
+		default:
+			return 0, fmt.Errorf("unknown field: %q", field)
+		}
+	}
+
+	if max := maxUpperHalfScore(game); game.UpperHalfScore() > max {
+		return 0, fmt.Errorf("invalid upper half score: %d, must be at most %d for the filled upper boxes",
+			game.UpperHalfScore(), max)
+	} else if !game.IsValid() {
+		return 0, fmt.Errorf("invalid game state: %q, the Yahtzee bonus requires a filled Yahtzee", s)
+	}
+
+	return game, nil
+}
+
+// maxUpperHalfScore returns the highest upper half score that can be
+// scored in the filled upper boxes (5 of each).
+func maxUpperHalfScore(game GameState) int {
+	max := 0
+	for box := Ones; box <= Sixes; box++ {
+		if game.BoxFilled(box) {
+			max += NDice * int(box+1)
+		}
+	}
+
+	return max
+}
+
+// ParseBox parses the name of a box, as returned by Box.String.
+// Names are not case sensitive.
+func ParseBox(s string) (Box, error) {
+	for box := Ones; box <= Yahtzee; box++ {
+		if strings.EqualFold(s, box.String()) {
+			return box, nil
+		}
+	}
+
+	return 0, fmt.Errorf("unknown box: %q", s)
+}
+
+// String returns the GameState in the notation parsed by ParseGameState.
+func (game GameState) String() string {
+	var fields []string
+	var filled []string
+	for box := Ones; box <= Yahtzee; box++ {
+		if game.BoxFilled(box) {
+			filled = append(filled, box.String())
+		}
+	}
+	if len(filled) > 0 {
+		fields = append(fields, "filled="+strings.Join(filled, ","))
+	}
+	if uhs := game.UpperHalfScore(); uhs > 0 {
+		fields = append(fields, fmt.Sprintf("uhs=%d", uhs))
+	}
+	if game.BonusEligible() {
+		fields = append(fields, "bonus")
+	}
+
+	if len(fields) == 0 {
+		return "new"
+	}
+
+	return strings.Join(fields, " ")
+}
+
+// ParseDice parses dice written as digits, optionally separated by
+// spaces or commas, e.g. "3 3 5 6 6", "33566" or "3,3,5,6,6". The dice
+// are returned in the order they were written. Each die must be 1-6,
+// and there may be at most NDice dice (but fewer are allowed, e.g.
+// for the dice that were rerolled).
+func ParseDice(s string) ([]int, error) {
+	var dice []int
+	for _, c := range s {
+		switch {
+		case c == ' ' || c == ',':
+			continue
+		case c >= '1' && c <= '0'+NSides:
+			dice = append(dice, int(c-'0'))
+		default:
+			return nil, fmt.Errorf("invalid die: %q, must be 1-%d", c, NSides)
+		}
+	}
+
+	if len(dice) > NDice {
+		return nil, fmt.Errorf("too many dice: %d > %d", len(dice), NDice)
+	}
+
+	return dice, nil
+}
+
+// ParseRoll parses a roll of exactly NDice dice, written as for ParseDice.
+func ParseRoll(s string) (Roll, error) {
+	dice, err := ParseDice(s)
+	if err != nil {
+		return NewRoll(), err
+	} else if len(dice) != NDice {
+		return NewRoll(), fmt.Errorf("invalid number of dice: %d != %d", len(dice), NDice)
+	}
+
+	return NewRollFromDice(dice), nil
+}
+
+// ParseHold parses the dice to hold, written as for ParseDice and
+// optionally preceded by "hold" (or "h"), e.g. "hold 33". An empty
+// hold (e.g. "hold") rerolls all of the dice.
+func ParseHold(s string) ([]int, error) {
+	s = strings.TrimSpace(s)
+	for _, prefix := range []string{"hold", "h"} {
+		if strings.HasPrefix(s, prefix) {
+			s = s[len(prefix):]
+			break
+		}
+	}
+
+	return ParseDice(s)
+}
@@ -0,0 +1,173 @@
+package yahtzee
+
+import (
+	"reflect"
+	"testing"
+)
+
+const allFilled = "filled=Ones,Twos,Threes,Fours,Fives,Sixes,ThreeOfAKind,FourOfAKind," +
+	"FullHouse,SmallStraight,LargeStraight,Chance,Yahtzee"
+
+func mustParseGameState(s string) GameState {
+	game, err := ParseGameState(s)
+	if err != nil {
+		panic(err)
+	}
+
+	return game
+}
+
+func TestParseGameState(t *testing.T) {
+	cases := []struct {
+		s        string
+		expected GameState
+	}{
+		{"new", NewGame()},
+		{"filled=Ones", NewGame().SetBoxFilled(Ones)},
+		{"filled=Fives,Sixes uhs=42", NewGame().SetBoxFilled(Fives).SetBoxFilled(Sixes).AddUpperHalfScore(42)},
+		{"filled=Ones,Twos uhs=15", NewGame().SetBoxFilled(Ones).SetBoxFilled(Twos).AddUpperHalfScore(15)},
+		{"filled=yahtzee,sixes bonus uhs=18",
+			NewGame().SetBoxFilled(Sixes).SetBoxFilled(Yahtzee).SetBonusEligible().AddUpperHalfScore(18)},
+		{"  filled=Chance   uhs=0 ", NewGame().SetBoxFilled(Chance)},
+	}
+
+	for _, tc := range cases {
+		game, err := ParseGameState(tc.s)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", tc.s, err)
+		} else if game != tc.expected {
+			t.Errorf("%q: expected %v got %v", tc.s, tc.expected, game)
+		}
+	}
+}
+
+func TestParseGameStateErrors(t *testing.T) {
+	cases := []string{
+		"",
+		"New",
+		"filled=",
+		"filled=Ones,",
+		"filled=Ones,Ones",
+		"filled=Ones filled=Twos",
+		"filled=Onez",
+		"uhs=42",
+		"filled=Ones,Twos uhs=16",
+		"filled=Chance uhs=1",
+		"uhs=64",
+		"uhs=-1",
+		"uhs=x",
+		"uhs=1 uhs=2",
+		"bonus",
+		"filled=Yahtzee bonus=true",
+		"filled=Yahtzee bonus bonus",
+		"new filled=Ones",
+		"123",
+	}
+
+	for _, s := range cases {
+		if game, err := ParseGameState(s); err == nil {
+			t.Errorf("%q: expected error, got %v", s, game)
+		}
+	}
+}
+
+func TestGameStateStringRoundTrip(t *testing.T) {
+	for game := NewGame(); game < MaxGame; game += 97 {
+		// Upper half scores that are not possible with the filled
+		// boxes are rejected by ParseGameState.
+		if !game.IsValid() || game.UpperHalfScore() > maxUpperHalfScore(game) {
+			continue
+		}
+
+		parsed, err := ParseGameState(game.String())
+		if err != nil {
+			t.Errorf("%d: error parsing %q: %v", uint(game), game.String(), err)
+		} else if parsed != game {
+			t.Errorf("%d: %q parsed as %d", uint(game), game.String(), uint(parsed))
+		}
+	}
+}
+
+func TestParseBox(t *testing.T) {
+	for box := Ones; box <= Yahtzee; box++ {
+		if parsed, err := ParseBox(box.String()); err != nil || parsed != box {
+			t.Errorf("%v: parsed as %v, %v", box, parsed, err)
+		}
+	}
+
+	if box, err := ParseBox("Full House"); err == nil {
+		t.Errorf("expected error, got %v", box)
+	}
+}
+
+func TestParseDice(t *testing.T) {
+	cases := []struct {
+		s        string
+		expected []int
+	}{
+		{"", nil},
+		{"6", []int{6}},
+		{"33566", []int{3, 3, 5, 6, 6}},
+		{"3 3 5 6 6", []int{3, 3, 5, 6, 6}},
+		{"6,5, 1", []int{6, 5, 1}},
+	}
+
+	for _, tc := range cases {
+		dice, err := ParseDice(tc.s)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", tc.s, err)
+		} else if !reflect.DeepEqual(dice, tc.expected) {
+			t.Errorf("%q: expected %v got %v", tc.s, tc.expected, dice)
+		}
+	}
+
+	for _, s := range []string{"0", "12370", "123456", "1-2", "1 2 x"} {
+		if dice, err := ParseDice(s); err == nil {
+			t.Errorf("%q: expected error, got %v", s, dice)
+		}
+	}
+}
+
+func TestParseRoll(t *testing.T) {
+	roll, err := ParseRoll("6 5 3 5 6")
+	if err != nil {
+		t.Fatal(err)
+	} else if expected := NewRollFromDice([]int{3, 5, 5, 6, 6}); roll != expected {
+		t.Errorf("expected %v got %v", expected, roll)
+	}
+
+	for _, s := range []string{"", "1234", "123456", "12340"} {
+		if roll, err := ParseRoll(s); err == nil {
+			t.Errorf("%q: expected error, got %v", s, roll)
+		}
+	}
+}
+
+func TestParseHold(t *testing.T) {
+	cases := []struct {
+		s        string
+		expected []int
+	}{
+		{"hold 33", []int{3, 3}},
+		{"33", []int{3, 3}},
+		{"hold", nil},
+		{"h 66", []int{6, 6}},
+		{"h", nil},
+		{" hold 1 2 3 4 5", []int{1, 2, 3, 4, 5}},
+	}
+
+	for _, tc := range cases {
+		dice, err := ParseHold(tc.s)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", tc.s, err)
+		} else if !reflect.DeepEqual(dice, tc.expected) {
+			t.Errorf("%q: expected %v got %v", tc.s, tc.expected, dice)
+		}
+	}
+
+	for _, s := range []string{"hold 7", "hold 123456", "holds 3", "hx"} {
+		if dice, err := ParseHold(s); err == nil {
+			t.Errorf("%q: expected error, got %v", s, dice)
+		}
+	}
+}
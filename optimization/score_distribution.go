@@ -2,6 +2,7 @@ package optimization
 
 import (
 	"encoding/gob"
+	"math"
 	"sync"
 
 	"github.com/timpalpant/yahtzee"
@@ -70,3 +71,73 @@ func (sd ScoreDistribution) Shift(offset int) GameResult {
 	copy(newSD[offset+1:], sd)
 	return newSD
 }
+
+// Mean returns the expected score.
+func (sd ScoreDistribution) Mean() float32 {
+	// Since sd[s] = P(score >= s), E[score] = sum_{s >= 1} sd[s].
+	var mean float64
+	for score := 1; score < len(sd); score++ {
+		mean += float64(sd[score])
+	}
+
+	return float32(mean)
+}
+
+// StdDev returns the standard deviation of the score.
+func (sd ScoreDistribution) StdDev() float32 {
+	// E[score^2] = sum_{s >= 1} (2s - 1) * sd[s].
+	var mean, meanSq float64
+	for score := 1; score < len(sd); score++ {
+		mean += float64(sd[score])
+		meanSq += float64(2*score-1) * float64(sd[score])
+	}
+
+	variance := meanSq - mean*mean
+	if variance < 0 { // Rounding error.
+		return 0
+	}
+
+	return float32(math.Sqrt(variance))
+}
+
+// Median returns the median score.
+func (sd ScoreDistribution) Median() int {
+	return sd.Quantile(0.5)
+}
+
+// Quantile returns the smallest score s such that the probability
+// of achieving a score of at most s is at least q.
+func (sd ScoreDistribution) Quantile(q float32) int {
+	for score := 0; score < len(sd)-1; score++ {
+		if 1-sd[score+1] >= q {
+			return score
+		}
+	}
+
+	return len(sd) - 1
+}
+
+// Quantiles returns the score at each of the given quantiles.
+func (sd ScoreDistribution) Quantiles(qs []float32) []int {
+	result := make([]int, len(qs))
+	for i, q := range qs {
+		result[i] = sd.Quantile(q)
+	}
+
+	return result
+}
+
+// ProbabilityInRange returns the probability of achieving
+// a score in the range [min, max].
+func (sd ScoreDistribution) ProbabilityInRange(min, max int) float32 {
+	if min < 0 {
+		min = 0
+	}
+	if min > max || min >= len(sd) {
+		return 0
+	} else if max+1 >= len(sd) {
+		return sd[min]
+	}
+
+	return sd[min] - sd[max+1]
+}
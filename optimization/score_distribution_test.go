@@ -0,0 +1,79 @@
+package optimization
+
+import (
+	"math"
+	"testing"
+)
+
+// newTestDistribution returns a distribution in which
+// a score of 10 or 20 is equally likely.
+func newTestDistribution() ScoreDistribution {
+	sd := NewScoreDistribution()
+	for score := 1; score <= 20; score++ {
+		if score <= 10 {
+			sd[score] = 1
+		} else {
+			sd[score] = 0.5
+		}
+	}
+
+	return sd
+}
+
+func TestScoreDistributionSummary(t *testing.T) {
+	sd := newTestDistribution()
+	if sd.GetProbability(0) != 1 {
+		t.Errorf("P(score >= 0) = %v, expected 1", sd.GetProbability(0))
+	}
+	if mean := sd.Mean(); mean != 15 {
+		t.Errorf("Mean = %v, expected 15", mean)
+	}
+	if stdDev := sd.StdDev(); math.Abs(float64(stdDev-5)) > 1e-4 {
+		t.Errorf("StdDev = %v, expected 5", stdDev)
+	}
+	if median := sd.Median(); median != 10 {
+		t.Errorf("Median = %v, expected 10", median)
+	}
+}
+
+func TestScoreDistributionQuantile(t *testing.T) {
+	sd := newTestDistribution()
+	cases := []struct {
+		q        float32
+		expected int
+	}{
+		{0, 0},
+		{0.25, 10},
+		{0.5, 10},
+		{0.75, 20},
+		{1, 20},
+	}
+
+	for _, tc := range cases {
+		if result := sd.Quantile(tc.q); result != tc.expected {
+			t.Errorf("Quantile(%v) = %v, expected %v", tc.q, result, tc.expected)
+		}
+	}
+}
+
+func TestScoreDistributionProbabilityInRange(t *testing.T) {
+	sd := newTestDistribution()
+	cases := []struct {
+		min, max int
+		expected float32
+	}{
+		{0, 9, 0},
+		{0, 10, 0.5},
+		{10, 20, 1},
+		{11, 19, 0},
+		{15, 5000, 0.5},
+		{20, 10, 0},
+	}
+
+	for _, tc := range cases {
+		if result := sd.ProbabilityInRange(tc.min, tc.max); result != tc.expected {
+			t.Errorf("ProbabilityInRange(%v, %v) = %v, expected %v",
+				tc.min, tc.max, result, tc.expected)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"runtime"
 	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/golang/glog"
@@ -125,14 +126,42 @@ func (s *Strategy) Compute(game yahtzee.GameState) GameResult {
 	return s.computeGame(game)
 }
 
+// Lookup returns the value of the given GameState if it is already
+// in the results table. Unlike Compute, it never computes missing values.
+func (s *Strategy) Lookup(game yahtzee.GameState) (GameResult, bool) {
+	if game.GameOver() {
+		return s.observable, true
+	}
+
+	return s.results.Get(uint(game))
+}
+
+// Count returns the number of GameStates in the results table.
+func (s *Strategy) Count() int {
+	return s.results.Count()
+}
+
 // cachePool maintains a reusable set of caches for TurnOptimizer,
 // to reduce memory pressure on the GC during calculation.
 var cachePool = sync.Pool{
 	New: func() interface{} {
+		atomic.AddInt64(&cachePoolStats.allocated, 1)
 		return NewCache(yahtzee.MaxRoll)
 	},
 }
 
+var cachePoolStats struct {
+	allocated int64
+	inUse     int64
+}
+
+// CachePoolStats returns the total number of TurnOptimizer caches that
+// have been allocated, and the number that are currently in use
+// (i.e. held by TurnOptimizers that have not been closed).
+func CachePoolStats() (allocated, inUse int64) {
+	return atomic.LoadInt64(&cachePoolStats.allocated), atomic.LoadInt64(&cachePoolStats.inUse)
+}
+
 // TurnOptimizer computes optimal choices for a single turn.
 // Once the strategy results table is fully populated, TurnOptimizer
 // is thread-safe as long as the caches are not shared.
@@ -148,6 +177,7 @@ func NewTurnOptimizer(strategy *Strategy, game yahtzee.GameState) *TurnOptimizer
 	held1Cache.Reset()
 	held2Cache := cachePool.Get().(*Cache)
 	held2Cache.Reset()
+	atomic.AddInt64(&cachePoolStats.inUse, 2)
 
 	return &TurnOptimizer{
 		strategy:   strategy,
@@ -157,9 +187,13 @@ func NewTurnOptimizer(strategy *Strategy, game yahtzee.GameState) *TurnOptimizer
 	}
 }
 
+// Close returns the caches of the TurnOptimizer to the pool. Results
+// returned by the TurnOptimizer may be reused once it is closed, and
+// must be copied if they are needed afterward.
 func (t *TurnOptimizer) Close() {
 	cachePool.Put(t.held1Cache)
 	cachePool.Put(t.held2Cache)
+	atomic.AddInt64(&cachePoolStats.inUse, -2)
 }
 
 func (t *TurnOptimizer) GetOptimalTurnOutcome() GameResult {
@@ -185,12 +219,18 @@ func (t *TurnOptimizer) GetHold1Outcomes(roll1 yahtzee.Roll) map[yahtzee.Roll]Ga
 	possibleHolds := roll1.PossibleHolds()
 	result := make(map[yahtzee.Roll]GameResult, len(possibleHolds))
 	for _, held1 := range possibleHolds {
-		result[held1] = t.expectationOverRolls(t.held1Cache, held1, t.GetBestHold2)
+		result[held1] = t.GetHold1Outcome(held1)
 	}
 
 	return result
 }
 
+// GetHold1Outcome returns the outcome of holding the given dice
+// after the first roll.
+func (t *TurnOptimizer) GetHold1Outcome(held1 yahtzee.Roll) GameResult {
+	return t.expectationOverRolls(t.held1Cache, held1, t.GetBestHold2)
+}
+
 func (t *TurnOptimizer) GetBestHold2(roll2 yahtzee.Roll) GameResult {
 	return t.maxOverHolds(roll2, func(held2 yahtzee.Roll) GameResult {
 		return t.expectationOverRolls(t.held2Cache, held2, t.GetBestFill)
@@ -201,12 +241,18 @@ func (t *TurnOptimizer) GetHold2Outcomes(roll2 yahtzee.Roll) map[yahtzee.Roll]Ga
 	possibleHolds := roll2.PossibleHolds()
 	result := make(map[yahtzee.Roll]GameResult, len(possibleHolds))
 	for _, held2 := range possibleHolds {
-		result[held2] = t.expectationOverRolls(t.held2Cache, held2, t.GetBestFill)
+		result[held2] = t.GetHold2Outcome(held2)
 	}
 
 	return result
 }
 
+// GetHold2Outcome returns the outcome of holding the given dice
+// after the second roll.
+func (t *TurnOptimizer) GetHold2Outcome(held2 yahtzee.Roll) GameResult {
+	return t.expectationOverRolls(t.held2Cache, held2, t.GetBestFill)
+}
+
 func (t *TurnOptimizer) GetBestFill(roll yahtzee.Roll) GameResult {
 	best := t.strategy.observable.Copy()
 	for _, box := range t.game.AvailableBoxes() {
@@ -223,15 +269,20 @@ func (t *TurnOptimizer) GetFillOutcomes(roll yahtzee.Roll) map[yahtzee.Box]GameR
 	availableBoxes := t.game.AvailableBoxes()
 	result := make(map[yahtzee.Box]GameResult, len(availableBoxes))
 	for _, box := range availableBoxes {
-		newGame, addedValue := t.game.FillBox(box, roll)
-		expectedRemainingScore := t.strategy.Compute(newGame)
-		expectedPositionValue := expectedRemainingScore.Shift(addedValue)
-		result[box] = expectedPositionValue
+		result[box] = t.GetFillOutcome(roll, box)
 	}
 
 	return result
 }
 
+// GetFillOutcome returns the outcome of playing the given roll
+// in the given box.
+func (t *TurnOptimizer) GetFillOutcome(roll yahtzee.Roll, box yahtzee.Box) GameResult {
+	newGame, addedValue := t.game.FillBox(box, roll)
+	expectedRemainingScore := t.strategy.Compute(newGame)
+	return expectedRemainingScore.Shift(addedValue)
+}
+
 func (t *TurnOptimizer) expectationOverRolls(cache *Cache, held yahtzee.Roll, rollValue func(roll yahtzee.Roll) GameResult) GameResult {
 	if result, ok := cache.Get(uint(held)); ok {
 		return result
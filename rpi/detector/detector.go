@@ -6,7 +6,6 @@ import (
 	"fmt"
 	"os"
 	"path"
-	"strconv"
 	"strings"
 	"sync"
 
@@ -231,22 +230,13 @@ func prompt(msg string) string {
 	return strings.TrimRight(result, "\n")
 }
 
+// parseRoll parses the dice entered by the annotator (see yahtzee.ParseDice),
+// in the order they were entered.
 func parseRoll(s string) ([]int, error) {
-	i, err := strconv.Atoi(s)
+	dice, err := yahtzee.ParseDice(s)
 	if err != nil {
 		return nil, err
-	}
-
-	dice := make([]int, 0, yahtzee.NDice)
-	for ; i > 0; i /= 10 {
-		die := i % 10
-		dice = append(dice, die)
-	}
-
-	// Reverse so that dice are in the order they were entered.
-	reverse(dice)
-
-	if len(dice) != yahtzee.NDice {
+	} else if len(dice) != yahtzee.NDice {
 		return dice, fmt.Errorf("Invalid number of dice: %v != %v",
 			len(dice), yahtzee.NDice)
 	}
@@ -254,12 +244,6 @@ func parseRoll(s string) ([]int, error) {
 	return dice, nil
 }
 
-func reverse(a []int) {
-	for left, right := 0, len(a)-1; left < right; left, right = left+1, right-1 {
-		a[left], a[right] = a[right], a[left]
-	}
-}
-
 func promptRoll() []int {
 	for {
 		rollStr := prompt("Enter roll: ")
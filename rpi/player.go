@@ -1,6 +1,7 @@
 package rpi
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -14,7 +15,7 @@ import (
 
 type YahtzeePlayer struct {
 	detector   *detector.YahtzeeDetector
-	client     *client.Client
+	advisor    client.Advisor
 	controller *controller.YahtzeeController
 
 	game         yahtzee.GameState
@@ -25,10 +26,10 @@ type YahtzeePlayer struct {
 }
 
 func NewYahtzeePlayer(detector *detector.YahtzeeDetector,
-	client *client.Client, controller *controller.YahtzeeController) *YahtzeePlayer {
+	advisor client.Advisor, controller *controller.YahtzeeController) *YahtzeePlayer {
 	return &YahtzeePlayer{
 		detector:   detector,
-		client:     client,
+		advisor:    advisor,
 		controller: controller,
 		game:       yahtzee.NewGame(),
 		turnStep:   yahtzee.Hold1,
@@ -36,9 +37,9 @@ func NewYahtzeePlayer(detector *detector.YahtzeeDetector,
 	}
 }
 
-func (yp *YahtzeePlayer) Play(scoreToBeat int) error {
+func (yp *YahtzeePlayer) Play(ctx context.Context, scoreToBeat int) error {
 	yp.controller.NewGame()
-	gameValue, err := yp.client.GetGameValue(yp.game, scoreToBeat)
+	gameValue, err := yp.advisor.GetGameValue(ctx, yp.game, scoreToBeat)
 	if err != nil {
 		return err
 	}
@@ -59,14 +60,14 @@ func (yp *YahtzeePlayer) Play(scoreToBeat int) error {
 		}
 
 		glog.Infof("Detected roll: %v", roll)
-		remainingScore := scoreToBeat - yp.currentScore
-		resp, err := yp.client.GetOptimalMove(yp.game, yp.turnStep, roll, remainingScore)
+		remainingScore := yp.remainingScore(scoreToBeat)
+		resp, err := yp.advisor.GetOptimalMove(ctx, yp.game, yp.turnStep, roll, remainingScore, yp.currentScore)
 		if err != nil {
 			return err
 		}
 
-		if scoreToBeat > 0 && resp.Value < gameValue {
-			glog.Info("Giving up and starting a new game")
+		if resp.NewGame {
+			glog.Infof("Giving up and starting a new game (margin: %g)", *resp.NewGameMargin)
 			break
 		}
 
@@ -75,7 +76,7 @@ func (yp *YahtzeePlayer) Play(scoreToBeat int) error {
 			fallthrough
 		case yahtzee.Hold2:
 			if len(resp.HeldDice) == yahtzee.NDice {
-				if err := yp.fillBoxEarly(roll, scoreToBeat); err != nil {
+				if err := yp.fillBoxEarly(ctx, roll, scoreToBeat); err != nil {
 					return err
 				}
 			} else {
@@ -103,6 +104,16 @@ func (yp *YahtzeePlayer) Play(scoreToBeat int) error {
 	return nil
 }
 
+// remainingScore returns the score needed over the remaining turns
+// to beat scoreToBeat, or 0 if it has already been beaten.
+func (yp *YahtzeePlayer) remainingScore(scoreToBeat int) int {
+	if remaining := scoreToBeat - yp.currentScore; remaining > 0 {
+		return remaining
+	}
+
+	return 0
+}
+
 func (yp *YahtzeePlayer) checkUnexpectedRollChange(roll []int) error {
 	if yp.prevRoll == nil {
 		return nil
@@ -167,9 +178,10 @@ func (yp *YahtzeePlayer) hold(roll, diceToKeep []int) error {
 	return nil
 }
 
-func (yp *YahtzeePlayer) fillBoxEarly(roll []int, scoreToBeat int) error {
+func (yp *YahtzeePlayer) fillBoxEarly(ctx context.Context, roll []int, scoreToBeat int) error {
 	// Hold all dice, i.e. skip to fill box.
-	resp, err := yp.client.GetOptimalMove(yp.game, yahtzee.FillBox, roll, scoreToBeat)
+	remainingScore := yp.remainingScore(scoreToBeat)
+	resp, err := yp.advisor.GetOptimalMove(ctx, yp.game, yahtzee.FillBox, roll, remainingScore, yp.currentScore)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,67 @@
+package yahtzee
+
+// Scorecard tracks the points scored in each box over the course
+// of a game, in addition to the GameState needed for optimization.
+//
+// Like GameState, a Scorecard is a value type: filling a box returns
+// a new Scorecard, so previous states may be retained cheaply (e.g. for undo).
+type Scorecard struct {
+	Game GameState
+	// BoxScores are the points played in each box, not including
+	// any bonuses. Boxes that have not been filled are 0.
+	BoxScores      [NumTurns]int
+	UpperHalfBonus int
+	YahtzeeBonus   int
+}
+
+func NewScorecard() Scorecard {
+	return Scorecard{Game: NewGame()}
+}
+
+// Fill plays the given roll in the given box, and returns the updated
+// Scorecard along with the total number of points added (including bonuses).
+func (sc Scorecard) Fill(box Box, roll Roll) (Scorecard, int) {
+	prevGame := sc.Game
+	newGame, value := prevGame.FillBox(box, roll)
+
+	boxScore := value
+	if prevGame.BonusEligible() && IsYahtzee(roll) {
+		sc.YahtzeeBonus += YahtzeeBonus
+		boxScore -= YahtzeeBonus
+	}
+
+	if box.IsUpperHalf() && prevGame.UpperHalfScore() < UpperHalfBonusThreshold &&
+		newGame.UpperHalfScore() >= UpperHalfBonusThreshold {
+		sc.UpperHalfBonus += UpperHalfBonus
+		boxScore -= UpperHalfBonus
+	}
+
+	sc.Game = newGame
+	sc.BoxScores[box] = boxScore
+	return sc, value
+}
+
+// UpperHalfScore returns the total points in the upper half boxes.
+// Unlike GameState.UpperHalfScore, it is not capped at the bonus threshold.
+func (sc Scorecard) UpperHalfScore() int {
+	total := 0
+	for box := Ones; box <= Sixes; box++ {
+		total += sc.BoxScores[box]
+	}
+	return total
+}
+
+// LowerHalfScore returns the total points in the lower half boxes.
+func (sc Scorecard) LowerHalfScore() int {
+	total := 0
+	for box := ThreeOfAKind; box <= Yahtzee; box++ {
+		total += sc.BoxScores[box]
+	}
+	return total
+}
+
+// Total returns the current score, including all bonuses.
+func (sc Scorecard) Total() int {
+	return sc.UpperHalfScore() + sc.UpperHalfBonus +
+		sc.LowerHalfScore() + sc.YahtzeeBonus
+}
@@ -0,0 +1,47 @@
+package yahtzee
+
+import (
+	"testing"
+)
+
+func TestScorecardFill(t *testing.T) {
+	sc := NewScorecard()
+	if sc.Total() != 0 {
+		t.Errorf("New scorecard should have 0 points, got %v", sc.Total())
+	}
+
+	sc, added := sc.Fill(Sixes, NewRollFromBase10Counts(500000))
+	if added != 30 || sc.BoxScores[Sixes] != 30 {
+		t.Errorf("Expected 30 points in Sixes, got %v (added %v)", sc.BoxScores[Sixes], added)
+	}
+
+	sc, _ = sc.Fill(Fives, NewRollFromBase10Counts(50000))
+	sc, added = sc.Fill(Fours, NewRollFromBase10Counts(5000))
+	if added != 20+UpperHalfBonus {
+		t.Errorf("Expected upper half bonus to be added, got %v", added)
+	}
+	if sc.BoxScores[Fours] != 20 {
+		t.Errorf("Bonus should not be included in box score, got %v", sc.BoxScores[Fours])
+	}
+	if sc.UpperHalfBonus != UpperHalfBonus {
+		t.Errorf("Expected upper half bonus %v, got %v", UpperHalfBonus, sc.UpperHalfBonus)
+	}
+	if sc.UpperHalfScore() != 75 {
+		t.Errorf("Upper half score should not be capped, got %v", sc.UpperHalfScore())
+	}
+
+	sc, _ = sc.Fill(Yahtzee, NewRollFromBase10Counts(50000))
+	sc, added = sc.Fill(FullHouse, NewRollFromBase10Counts(500000))
+	if added != 25+YahtzeeBonus {
+		t.Errorf("Expected joker full house with bonus, got %v", added)
+	}
+	if sc.BoxScores[FullHouse] != 25 || sc.YahtzeeBonus != YahtzeeBonus {
+		t.Errorf("Expected 25 in FullHouse and bonus %v, got %v and %v",
+			YahtzeeBonus, sc.BoxScores[FullHouse], sc.YahtzeeBonus)
+	}
+
+	expected := 30 + 25 + 20 + UpperHalfBonus + 50 + 25 + YahtzeeBonus
+	if sc.Total() != expected {
+		t.Errorf("Expected total %v, got %v", expected, sc.Total())
+	}
+}
@@ -1,9 +1,43 @@
 package server
 
 import (
+	"fmt"
+
 	"github.com/timpalpant/yahtzee"
 )
 
+// ErrorResponse is the body returned with any non-200 status code.
+type ErrorResponse struct {
+	Error APIError
+}
+
+// APIError describes why a request failed.
+type APIError struct {
+	// Code is a machine-readable error code, e.g. "invalid_dice".
+	// See the ErrCode constants.
+	Code string
+	// Field is the path of the invalid request field,
+	// e.g. "TurnState.Dice[2]", if applicable.
+	Field   string `json:",omitempty"`
+	Message string
+
+	// status is the HTTP status code to respond with.
+	status int
+}
+
+func (e *APIError) Error() string {
+	if e.Field != "" {
+		return fmt.Sprintf("%s: %s (%s)", e.Field, e.Message, e.Code)
+	}
+
+	return fmt.Sprintf("%s (%s)", e.Message, e.Code)
+}
+
+// StatusCode is the HTTP status code the server responds with for e.
+func (e *APIError) StatusCode() int {
+	return e.status
+}
+
 // GameState represents the current state of the game at the beginning
 // of the turn.
 type GameState struct {
@@ -49,6 +83,12 @@ func (gs GameState) ToYahtzeeGameState() yahtzee.GameState {
 type TurnState struct {
 	Step yahtzee.TurnStep
 	Dice []int
+	// HeldDice may be provided with Step Hold1 or Hold2 to describe a
+	// partial turn, in which the dice to keep have already been chosen.
+	// An empty (non-null) list holds none of the dice.
+	// HeldDice are used by OutcomeDistribution, and by OptimalMove
+	// (which then returns the value of holding them).
+	HeldDice []int `json:",omitempty"`
 }
 
 // GetScoreRequest gets the score generated by playing the given
@@ -65,29 +105,108 @@ type GetScoreResponse struct {
 	Score int
 }
 
+// Objective is the quantity that a move is chosen to optimize.
+type Objective string
+
+const (
+	// ObjectiveExpectedValue maximizes the expected final score.
+	ObjectiveExpectedValue Objective = "expected_value"
+	// ObjectiveProbabilityToBeat maximizes the probability of
+	// achieving a final score of at least ScoreToBeat in this game.
+	ObjectiveProbabilityToBeat Objective = "probability_to_beat"
+	// ObjectiveExpectedWork minimizes the expected amount of play
+	// (including starting new games) needed to achieve ScoreToBeat.
+	ObjectiveExpectedWork Objective = "expected_work"
+)
+
+// table returns the name of the strategy table that optimizes the objective.
+func (o Objective) table() string {
+	switch o {
+	case ObjectiveProbabilityToBeat:
+		return TableScoreDistribution
+	case ObjectiveExpectedWork:
+		return TableExpectedWork
+	}
+
+	return TableExpectedValue
+}
+
 // OptimalMoveRequest gets the best move to make given the current
 // turn state.
 type OptimalMoveRequest struct {
 	GameState GameState
 	TurnState TurnState
 
-	// If ScoreToBeat is provided, then the result will be the move
-	// that maximizes the probability of achieving a final score
-	// greater than the given score.
+	// ScoreToBeat is the score to achieve over the remaining turns.
+	// It is required for the probability_to_beat and expected_work objectives.
 	ScoreToBeat int
+
+	// Objective is the quantity to optimize. If it is not provided,
+	// the objective is expected_value if ScoreToBeat is 0, and
+	// expected_work otherwise.
+	Objective Objective `json:",omitempty"`
+
+	// CurrentScore is the number of points scored so far in this game.
+	// It is used with ScoreToBeat to decide whether it would be better
+	// to give up and start a new game.
+	CurrentScore int `json:",omitempty"`
+}
+
+func (req *OptimalMoveRequest) objective() Objective {
+	if req.Objective != "" {
+		return req.Objective
+	} else if req.ScoreToBeat > 0 {
+		return ObjectiveExpectedWork
+	}
+
+	return ObjectiveExpectedValue
+}
+
+// requiredTables returns the names of the tables needed to compute
+// the response: the table of the objective, and the expected value
+// table that is always reported. The values of the move for the other
+// objectives are only reported if their tables are loaded.
+func (req *OptimalMoveRequest) requiredTables() []string {
+	return []string{TableExpectedValue, req.objective().table()}
 }
 
 // Optimal move response returns the best move to make.
 type OptimalMoveResponse struct {
 	// HeldDice are returned if the TurnState of the request is
-	// Hold1 or Hold2.
+	// Hold1 or Hold2. They are the HeldDice of the TurnState, if provided.
 	HeldDice []int
 	// BoxFilled is returned if the TurnState of the request is FillBox.
 	BoxFilled int
-	// Value is the value attributed to this move.
-	// For expected value, it is the expected remaining value.
-	// For ScoreToBeat, it is the probability of beating the score.
+	// Value is the value attributed to this move by the objective.
+	// For expected_value, it is the expected remaining score.
+	// For probability_to_beat, it is the probability of beating the score.
+	// For expected_work, it is the negative expected work.
 	Value float32
+	// Values are the value of this move for every objective.
+	Values ObjectiveValues
+	// NewGame is true if giving up and starting a new game is expected
+	// to take less work to achieve CurrentScore + ScoreToBeat than
+	// continuing with this move. It is only set if ScoreToBeat > 0
+	// and the expected_work table is loaded.
+	NewGame bool
+	// NewGameMargin is the expected work saved by starting a new game
+	// instead of continuing (negative if continuing is better).
+	// It is omitted if NewGame is not set.
+	NewGameMargin *float32 `json:",omitempty"`
+}
+
+// ObjectiveValues report the value of a move for each Objective.
+type ObjectiveValues struct {
+	// ExpectedValue is the expected score over the remaining turns.
+	ExpectedValue float32
+	// ProbabilityToBeat is the probability of achieving at least
+	// ScoreToBeat over the remaining turns (1 if ScoreToBeat is 0).
+	// It is omitted if the score_distribution table is not loaded.
+	ProbabilityToBeat *float32 `json:",omitempty"`
+	// ExpectedWork is the expected amount of play remaining, including
+	// new games, to achieve ScoreToBeat (0 if ScoreToBeat is 0).
+	// It is omitted if the expected_work table is not loaded.
+	ExpectedWork *float32 `json:",omitempty"`
 }
 
 // OutcomeDistributionRequest gets the range of possible outcomes
@@ -95,27 +214,364 @@ type OptimalMoveResponse struct {
 type OutcomeDistributionRequest struct {
 	GameState GameState
 	TurnState TurnState
+
+	// Quantiles of the final score to report for each outcome.
+	// If not provided, DefaultQuantiles are reported.
+	Quantiles []float32 `json:",omitempty"`
+	// Ranges of final scores for which to report the probability
+	// that the final score falls in the range.
+	Ranges []ScoreRange `json:",omitempty"`
+	// Compact omits the FinalScoreDistribution of each outcome,
+	// so that only the summary statistics are returned.
+	Compact bool `json:",omitempty"`
+}
+
+// DefaultQuantiles are the quantiles reported in a DistributionSummary
+// if none are requested.
+var DefaultQuantiles = []float32{0.05, 0.25, 0.5, 0.75, 0.95}
+
+// ScoreRange is an inclusive range of final scores.
+type ScoreRange struct {
+	Min int
+	Max int
 }
 
 // HoldChoices are populated if TurnState.Step is Hold1 or Hold2.
-// FillChoices are populated if TurnState.Step is FillBox (after third roll).
+// FillChoices are populated if TurnState.Step is Hold1, Hold2 or FillBox.
+// Summary is populated instead if TurnState.Step is Begin, or if
+// TurnState.HeldDice are provided.
 type OutcomeDistributionResponse struct {
 	HoldChoices []HoldChoice
 	FillChoices []FillChoice
+	Summary     *OutcomeSummary `json:",omitempty"`
+}
+
+// OutcomeSummary describes the final score over the remaining turns
+// of the game from the requested position.
+type OutcomeSummary struct {
+	ExpectedFinalScore float32
+	DistributionSummary
+	FinalScoreDistribution []float32 `json:",omitempty"`
+}
+
+// DistributionSummary reports summary statistics of a
+// FinalScoreDistribution.
+type DistributionSummary struct {
+	Mean   float32
+	Median int
+	StdDev float32
+	// Quantiles of the final score, in the order requested.
+	Quantiles []ScoreQuantile
+	// Ranges are the probability of each requested ScoreRange.
+	Ranges []RangeProbability `json:",omitempty"`
+}
+
+// ScoreQuantile is the score that will not be exceeded
+// with probability Quantile.
+type ScoreQuantile struct {
+	Quantile float32
+	Score    int
+}
+
+// RangeProbability is the probability of achieving
+// a final score in the range [Min, Max].
+type RangeProbability struct {
+	Min         int
+	Max         int
+	Probability float32
 }
 
 // HoldChoice represents one possible choice of dice to hold,
 // and the associated final outcome if that choice is made.
 type HoldChoice struct {
-	HeldDice               []int
-	ExpectedFinalScore     float32
-	FinalScoreDistribution []float32
+	HeldDice           []int
+	ExpectedFinalScore float32
+	DistributionSummary
+	FinalScoreDistribution []float32 `json:",omitempty"`
 }
 
 // FillChoice represents the outcome of filling a particular box with
 // a given roll.
 type FillChoice struct {
-	BoxFilled              int
-	ExpectedFinalScore     float32
-	FinalScoreDistribution []float32
+	BoxFilled          int
+	ExpectedFinalScore float32
+	DistributionSummary
+	FinalScoreDistribution []float32 `json:",omitempty"`
+}
+
+// CreateGameRequest starts a new game session on the server.
+type CreateGameRequest struct {
+	// If ScoreToBeat is provided, advice for the session will maximize
+	// the probability of achieving a final score greater than ScoreToBeat.
+	ScoreToBeat int
+}
+
+// RollRequest records the dice rolled in a game session.
+// Dice must include any dice held from the previous roll.
+type RollRequest struct {
+	Dice []int
+}
+
+// HoldRequest records the dice held before the next roll.
+// Holding all dice skips directly to filling a box.
+type HoldRequest struct {
+	HeldDice []int
+}
+
+// FillRequest plays the current dice in the given box.
+type FillRequest struct {
+	Box int
+}
+
+// GameSession is the current state of a game session.
+type GameSession struct {
+	ID          string
+	ScoreToBeat int
+	GameState   GameState
+	TurnState   TurnState
+	// HeldDice are the dice that will be kept for the next roll.
+	HeldDice  []int
+	Scorecard Scorecard
+	GameOver  bool
+}
+
+// Scorecard summarizes the points scored so far in a game session.
+type Scorecard struct {
+	Boxes          []BoxScore
+	UpperHalfScore int
+	UpperHalfBonus int
+	YahtzeeBonus   int
+	Total          int
+}
+
+// BoxScore is the score played in a single box.
+type BoxScore struct {
+	Box    int
+	Name   string
+	Filled bool
+	Score  int
+}
+
+func FromYahtzeeScorecard(sc yahtzee.Scorecard) Scorecard {
+	boxes := make([]BoxScore, yahtzee.NumTurns)
+	for box := yahtzee.Ones; box <= yahtzee.Yahtzee; box++ {
+		boxes[box] = BoxScore{
+			Box:    int(box),
+			Name:   box.String(),
+			Filled: sc.Game.BoxFilled(box),
+			Score:  sc.BoxScores[box],
+		}
+	}
+
+	return Scorecard{
+		Boxes:          boxes,
+		UpperHalfScore: sc.UpperHalfScore(),
+		UpperHalfBonus: sc.UpperHalfBonus,
+		YahtzeeBonus:   sc.YahtzeeBonus,
+		Total:          sc.Total(),
+	}
+}
+
+// LiveAdviceRequest is sent over the live advice WebSocket whenever
+// the dice change. Requests for a roll with some, but fewer than 5,
+// dice (i.e. dice that are still being entered) are ignored.
+type LiveAdviceRequest struct {
+	GameState   GameState
+	TurnState   TurnState
+	ScoreToBeat int
+}
+
+// LiveAdviceResponse is pushed over the live advice WebSocket in
+// response to each LiveAdviceRequest that is not ignored. Choices are ordered
+// from best to worst: by ProbabilityToBeat if a ScoreToBeat was
+// provided, and by ExpectedFinalScore otherwise.
+type LiveAdviceResponse struct {
+	TurnState   TurnState
+	HoldChoices []HoldAdvice
+	FillChoices []FillAdvice
+	// Error is set if advice could not be computed for the request.
+	Error *APIError `json:",omitempty"`
+}
+
+// HoldAdvice summarizes the outcome of holding the given dice.
+type HoldAdvice struct {
+	HeldDice           []int
+	ExpectedFinalScore float32
+	ProbabilityToBeat  float32
+}
+
+// FillAdvice summarizes the outcome of filling the given box.
+type FillAdvice struct {
+	BoxFilled          int
+	ExpectedFinalScore float32
+	ProbabilityToBeat  float32
+}
+
+// ExplainRequest asks for every choice available in a position,
+// ranked from best to worst, with an explanation of each.
+type ExplainRequest struct {
+	GameState GameState
+	TurnState TurnState
+	// ScoreToBeat is the score to achieve over the remaining turns.
+	// If it is provided, choices are ranked by the probability of
+	// beating it instead of by expected final score.
+	ScoreToBeat int `json:",omitempty"`
+}
+
+// ExplainResponse explains every choice available in a position.
+// HoldChoices are populated if TurnState.Step is Hold1 or Hold2.
+// FillChoices are populated if TurnState.Step is Hold1, Hold2 or FillBox.
+// Choices are ordered from best to worst, as in LiveAdviceResponse.
+type ExplainResponse struct {
+	HoldChoices []ExplainedHold
+	FillChoices []ExplainedFill
+}
+
+// ExplainedHold explains the choice of holding the given dice.
+type ExplainedHold struct {
+	HeldDice           []int
+	ExpectedFinalScore float32
+	ProbabilityToBeat  float32
+	Explanation
+}
+
+// ExplainedFill explains the choice of filling the given box.
+type ExplainedFill struct {
+	BoxFilled          int
+	ExpectedFinalScore float32
+	ProbabilityToBeat  float32
+	Explanation
+}
+
+// Explanation compares a choice to the best choice available,
+// and describes what the choice is trying to achieve.
+type Explanation struct {
+	// Rank is the position of this choice among all hold and fill
+	// choices, starting from 1 for the best choice.
+	Rank int
+	// ExpectedValueGap is how much lower the expected final score is
+	// than that of the choice with the highest expected final score.
+	ExpectedValueGap float32
+	// ProbabilityToBeatGap is how much lower the probability of beating
+	// ScoreToBeat is than that of the choice most likely to beat it.
+	// It is 0 if ScoreToBeat was not provided.
+	ProbabilityToBeatGap float32
+	Category             ChoiceCategory
+	// Description is a human-readable summary of the choice,
+	// e.g. "keeping 6s for the upper bonus".
+	Description string
+}
+
+// ChoiceCategory classifies what a choice is trying to achieve.
+type ChoiceCategory string
+
+// Categories of hold choices.
+const (
+	CategoryKeepAll       ChoiceCategory = "keep_all"
+	CategoryRerollAll     ChoiceCategory = "reroll_all"
+	CategoryYahtzee       ChoiceCategory = "yahtzee"
+	CategoryOfAKind       ChoiceCategory = "of_a_kind"
+	CategoryFullHouse     ChoiceCategory = "full_house"
+	CategorySmallStraight ChoiceCategory = "small_straight"
+	CategoryLargeStraight ChoiceCategory = "large_straight"
+	CategoryUpperBonus    ChoiceCategory = "upper_bonus"
+	CategoryUpper         ChoiceCategory = "upper"
+	CategoryChance        ChoiceCategory = "chance"
+	CategoryOther         ChoiceCategory = "other"
+)
+
+// Categories of fill choices. Fills may also be CategoryUpperBonus.
+const (
+	CategoryScore        ChoiceCategory = "score"
+	CategoryYahtzeeBonus ChoiceCategory = "yahtzee_bonus"
+	CategoryScratch      ChoiceCategory = "scratch"
+)
+
+// BatchOptimalMoveRequest evaluates many positions in a single request.
+// The body may be either a JSON array of OptimalMoveRequests, or a
+// stream of OptimalMoveRequests in JSON Lines format (one per line).
+//
+// The response is a stream of BatchResults in JSON Lines format,
+// in the same order as the requests. Items that cannot be parsed or
+// evaluated have an Error, and the rest of the batch is evaluated.
+//
+// A batch may have at most MaxBatchSize items of MaxBatchItemBytes
+// each. If the batch cannot be read (e.g. it has too many items, a
+// JSON array is malformed, or there is data after the end of the
+// array), the request fails with 400 Bad Request and no results.
+type BatchOptimalMoveRequest []OptimalMoveRequest
+
+// BatchResult is the result for a single item in a batch request.
+// Exactly one of Response or Error is set.
+type BatchResult struct {
+	Index    int
+	Response *OptimalMoveResponse `json:",omitempty"`
+	Error    *APIError            `json:",omitempty"`
+}
+
+// ServerStatus is returned by the /healthz and /readyz endpoints.
+type ServerStatus struct {
+	// Ready is true once all tables have finished loading,
+	// and at least one of them was loaded successfully.
+	Ready bool
+	// Degraded is true if any of the tables are not loaded. Requests
+	// that require a missing table fail with 503 Service Unavailable.
+	Degraded bool
+	Tables   []TableStatus
+}
+
+// TableStatus reports whether a strategy table is available.
+type TableStatus struct {
+	Name string
+	// State is one of "unavailable" (not configured), "loading",
+	// "loaded" or "failed".
+	State       string
+	File        string  `json:",omitempty"`
+	Error       string  `json:",omitempty"`
+	LoadSeconds float64 `json:",omitempty"`
+}
+
+// ReloadRequest reloads strategy tables without restarting the server.
+// New tables are loaded alongside the current ones, and only replace
+// them if they are valid and their E_0 (the value of a new game) has
+// not changed by more than MaxE0Change.
+type ReloadRequest struct {
+	// Tables maps the name of each table to reload to the file to load
+	// it from, which must be the name of a file in the server's table
+	// directory. If empty, all tables are reloaded from their current files.
+	Tables map[string]string `json:",omitempty"`
+	// MaxE0Change is the largest relative change in E_0 to accept.
+	// If not provided, DefaultMaxE0Change is used.
+	MaxE0Change float64 `json:",omitempty"`
+	// Force replaces the tables regardless of the change in E_0.
+	Force bool `json:",omitempty"`
+}
+
+// ReloadResponse reports the result of reloading each table.
+type ReloadResponse struct {
+	Tables []ReloadResult
+}
+
+// ReloadResult is the result of reloading a single table.
+// If Error is set, the previous table is still in use.
+type ReloadResult struct {
+	Name     string
+	File     string
+	E0Change float64
+	Error    *APIError `json:",omitempty"`
+}
+
+// GameStateInfo is returned by the /debug/game_state endpoint.
+type GameStateInfo struct {
+	// ID is the integer that represents the GameState in the
+	// strategy tables.
+	ID uint
+	// Notation is the GameState in the notation parsed by
+	// yahtzee.ParseGameState, e.g. "filled=Ones,Yahtzee uhs=3 bonus".
+	Notation       string
+	GameState      GameState
+	TurnsRemaining int
+	// ExpectedValue is the expected remaining score, if the GameState
+	// is in the expected value table. It is never computed on demand.
+	ExpectedValue *float32 `json:",omitempty"`
 }
@@ -0,0 +1,134 @@
+package server
+
+//go:generate go run gen_assets.go
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+)
+
+// assets provides the web UI templates and static files, either
+// from the copies embedded in the binary or from a directory.
+type assets struct {
+	// dir is the directory containing the templates/ and static/
+	// directories. If empty, the embedded assets are used.
+	dir string
+}
+
+// read returns the contents of the asset with the given slash-separated
+// path, e.g. "static/js/yahtzee.js".
+func (a assets) read(name string) ([]byte, error) {
+	if a.dir != "" {
+		return ioutil.ReadFile(filepath.Join(a.dir, filepath.FromSlash(name)))
+	}
+
+	data, ok := embeddedAssets[name]
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+
+	return []byte(data), nil
+}
+
+// parseTemplates parses all of the page templates.
+func (a assets) parseTemplates() (*template.Template, error) {
+	t := template.New("")
+	for _, name := range []string{"index.html", "error.html"} {
+		data, err := a.read("templates/" + name)
+		if err != nil {
+			return nil, err
+		}
+
+		if _, err := t.New(name).Parse(string(data)); err != nil {
+			return nil, err
+		}
+	}
+
+	return t, nil
+}
+
+// SetAssetsDir serves the web UI templates and static files from the
+// given directory instead of the copies embedded in the binary.
+// The directory should contain templates/ and static/ directories.
+func (ys *YahtzeeServer) SetAssetsDir(dir string) error {
+	a := assets{dir}
+	t, err := a.parseTemplates()
+	if err != nil {
+		return err
+	}
+
+	ys.assets = a
+	ys.templates = t
+	return nil
+}
+
+func (ys *YahtzeeServer) Index(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		ys.writeErrorPage(w, http.StatusNotFound, "Page not found: "+r.URL.Path)
+		return
+	}
+
+	ys.executeTemplate(w, http.StatusOK, "index.html", struct{}{})
+}
+
+// Static serves the static files of the web UI under /static/.
+func (ys *YahtzeeServer) Static(w http.ResponseWriter, r *http.Request) {
+	name := path.Clean("/" + strings.TrimPrefix(r.URL.Path, "/static/"))
+	data, err := ys.assets.read("static" + name)
+	if os.IsNotExist(err) {
+		ys.writeErrorPage(w, http.StatusNotFound, "File not found: "+r.URL.Path)
+		return
+	} else if err != nil {
+		glog.Error(err)
+		ys.writeErrorPage(w, http.StatusInternalServerError, "Error reading file: "+r.URL.Path)
+		return
+	}
+
+	http.ServeContent(w, r, name, time.Time{}, bytes.NewReader(data))
+}
+
+// errorPage is the data used to render the error page template.
+type errorPage struct {
+	Status     int
+	StatusText string
+	Message    string
+}
+
+func (ys *YahtzeeServer) writeErrorPage(w http.ResponseWriter, status int, message string) {
+	ys.executeTemplate(w, status, "error.html", errorPage{
+		Status:     status,
+		StatusText: http.StatusText(status),
+		Message:    message,
+	})
+}
+
+// executeTemplate renders the named template with the given status code.
+// If the template fails, the error page is rendered instead.
+func (ys *YahtzeeServer) executeTemplate(w http.ResponseWriter, status int, name string, data interface{}) {
+	var buf bytes.Buffer
+	if err := ys.templates.ExecuteTemplate(&buf, name, data); err != nil {
+		glog.Error(err)
+		if name == "error.html" {
+			http.Error(w, fmt.Sprintf("%d %s", status, http.StatusText(status)), status)
+		} else {
+			ys.writeErrorPage(w, http.StatusInternalServerError, "Error rendering page")
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	if _, err := buf.WriteTo(w); err != nil {
+		glog.Warning(err)
+	}
+}
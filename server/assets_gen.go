@@ -0,0 +1,19 @@
+// Code generated by gen_assets.go; DO NOT EDIT.
+
+package server
+
+// embeddedAssets are the contents of the web UI templates and
+// static files, by path relative to the server package.
+var embeddedAssets = map[string]string{
+	"static/css/yahtzee.css":   ".dice-row {\n    display: flex;\n    justify-content: space-between;\n}\n\n.die {\n    text-align: center;\n}\n\n.roll-btn {\n    height: 52px;\n    width: 100px;\n    margin-top: 27px;\n    padding-top: 11px;\n}\n\n.expected-score-container {\n    padding-bottom: 30px;\n}\n\n.box {\n    text-align: center;\n}\n\n.box button {\n    padding: 1px 8px 1px 8px;\n}\n\n.new-game-container {\n    text-align: center;\n    margin: 30px 0 30px 0;\n}\n\n.score-box {\n    text-align: center;\n}\n\n#game-type-selector {\n    margin: 0 10px 0 10px;\n}\n\n#high-score-input {\n    width: 100px;\n}\n",
+	"static/images/blank.svg":  "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n<svg width=\"512px\" height=\"512px\" viewBox=\"0 0 512 512\" version=\"1.1\" xmlns=\"http://www.w3.org/2000/svg\" xmlns:xlink=\"http://www.w3.org/1999/xlink\" xmlns:sketch=\"http://www.bohemiancoding.com/sketch/ns\">\n    <title>blank</title>\n    <description>Created with Sketch (http://www.bohemiancoding.com/sketch)</description>\n    <defs></defs>\n    <g id=\"Page-1\" stroke=\"none\" stroke-width=\"1\" fill=\"none\" fill-rule=\"evenodd\" sketch:type=\"MSPage\">\n        <g id=\"blank\" sketch:type=\"MSLayerGroup\">\n            <path d=\"M0,0 L512,0 L512,512 L0,512 L0,0 Z\" id=\"Shape\" fill=\"#FFFFFF\" sketch:type=\"MSShapeGroup\"></path>\n            <g id=\"Group\" transform=\"translate(27.000000, 27.000000)\" fill=\"#000000\" sketch:type=\"MSShapeGroup\">\n                <path d=\"M457.5,401.02 C457.5,415.999427 451.549446,430.365336 440.957391,440.957391 C430.365336,451.549446 415.999427,457.5 401.02,457.5 L57.02,457.5 C42.0336434,457.510614 27.6574561,451.564745 17.0567486,440.97154 C6.45604112,430.378335 0.499996242,416.00636 0.5,401.02 L0.5,57.02 C0.48938643,42.0336434 6.43525497,27.6574561 17.0284602,17.0567486 C27.6216654,6.45604112 41.9936396,0.499996242 56.98,0.5 L400.98,0.5 C415.966357,0.48938643 430.342544,6.43525497 440.943251,17.0284602 C451.543959,27.6216654 457.500004,41.9936396 457.5,56.98 L457.5,401.02 Z M437.5,57.02 C437.510616,47.3379738 433.671886,38.0488637 426.829408,31.1988829 C419.98693,24.3489021 410.702032,20.4999942 401.02,20.5 L57.02,20.5 C47.3379738,20.4893837 38.0488637,24.3281141 31.1988829,31.1705922 C24.3489021,38.0130703 20.4999942,47.297968 20.5,56.98 L20.5,400.98 C20.4893837,410.662026 24.3281141,419.951136 31.1705922,426.801117 C38.0130703,433.651098 47.297968,437.500006 56.98,437.5 L400.98,437.5 C410.662026,437.510616 419.951136,433.671886 426.801117,426.829408 C433.651098,419.98693 437.500006,410.702032 437.5,401.02 L437.5,57.02 Z\" id=\"Shape\"></path>\n            </g>\n        </g>\n    </g>\n</svg>",
+	"static/images/fives.svg":  "<svg xmlns=\"http://www.w3.org/2000/svg\" viewBox=\"0 0 512 512\" style=\"height: 512px; width: 512px;\"><path d=\"M0 0h512v512H0z\" fill=\"#ffffff\"></path><g class=\"\" transform=\"translate(0,0)\" style=\"\"><path fill=\"#000000\" d=\"M302.87 255.5a47.37 47.37 0 1 1-47.37-47.37 47.37 47.37 0 0 1 47.37 47.37zM128.5 81.18a47.37 47.37 0 1 0 47.41 47.32 47.37 47.37 0 0 0-47.41-47.32zm253.91 0a47.37 47.37 0 1 0 47.41 47.32 47.37 47.37 0 0 0-47.32-47.32zM128.5 335.09a47.37 47.37 0 1 0 47.41 47.41 47.37 47.37 0 0 0-47.41-47.41zm253.91 0a47.37 47.37 0 1 0 47.41 47.41 47.37 47.37 0 0 0-47.32-47.41zm102 92.93a56.48 56.48 0 0 1-56.39 56.48h-344a56.48 56.48 0 0 1-56.52-56.48v-344A56.48 56.48 0 0 1 83.98 27.5h344a56.48 56.48 0 0 1 56.52 56.48zm-20-344a36.48 36.48 0 0 0-36.39-36.52h-344A36.48 36.48 0 0 0 47.5 83.98v344a36.48 36.48 0 0 0 36.48 36.52h344a36.48 36.48 0 0 0 36.52-36.48z\"></path></g><!-- react-empty: 6 --></svg>",
+	"static/images/fours.svg":  "<svg xmlns=\"http://www.w3.org/2000/svg\" viewBox=\"0 0 512 512\" style=\"height: 512px; width: 512px;\"><path d=\"M0 0h512v512H0z\" fill=\"#ffffff\"></path><g class=\"\" transform=\"translate(0,0)\" style=\"\"><path fill=\"#000000\" d=\"M175.91 128.5a47.37 47.37 0 1 1-47.41-47.32 47.37 47.37 0 0 1 47.41 47.32zM382.5 81.18a47.37 47.37 0 1 0 47.32 47.32 47.37 47.37 0 0 0-47.32-47.32zm-254 253.91a47.37 47.37 0 1 0 47.41 47.41 47.37 47.37 0 0 0-47.41-47.41zm253.91 0a47.37 47.37 0 1 0 47.41 47.41 47.37 47.37 0 0 0-47.32-47.41zm102 92.93a56.48 56.48 0 0 1-56.39 56.48h-344a56.48 56.48 0 0 1-56.52-56.48v-344A56.48 56.48 0 0 1 83.98 27.5h344a56.48 56.48 0 0 1 56.52 56.48zm-20-344a36.48 36.48 0 0 0-36.39-36.52h-344A36.48 36.48 0 0 0 47.5 83.98v344a36.48 36.48 0 0 0 36.48 36.52h344a36.48 36.48 0 0 0 36.52-36.48z\"></path></g><!-- react-empty: 6 --></svg>",
+	"static/images/ones.svg":   "<svg xmlns=\"http://www.w3.org/2000/svg\" viewBox=\"0 0 512 512\" style=\"height: 512px; width: 512px;\"><path d=\"M0 0h512v512H0z\" fill=\"#ffffff\"></path><g class=\"\" transform=\"translate(0,0)\" style=\"\"><path fill=\"#000000\" d=\"M302.87 255.5a47.37 47.37 0 1 1-47.37-47.37 47.37 47.37 0 0 1 47.37 47.37zM484.5 428.02a56.48 56.48 0 0 1-56.48 56.48h-344a56.48 56.48 0 0 1-56.52-56.48v-344A56.48 56.48 0 0 1 83.98 27.5h344a56.48 56.48 0 0 1 56.52 56.48zm-20-344a36.48 36.48 0 0 0-36.48-36.52h-344A36.48 36.48 0 0 0 47.5 83.98v344a36.48 36.48 0 0 0 36.48 36.52h344a36.48 36.48 0 0 0 36.52-36.48z\"></path></g><!-- react-empty: 6 --></svg>",
+	"static/images/sixes.svg":  "<svg xmlns=\"http://www.w3.org/2000/svg\" viewBox=\"0 0 512 512\" style=\"height: 512px; width: 512px;\"><path d=\"M0 0h512v512H0z\" fill=\"#ffffff\"></path><g class=\"\" transform=\"translate(0,0)\" style=\"\"><path fill=\"#000000\" d=\"M175.91 128.5a47.37 47.37 0 1 1-47.41-47.32 47.37 47.37 0 0 1 47.41 47.32zM382.5 81.18a47.37 47.37 0 1 0 47.32 47.32 47.37 47.37 0 0 0-47.32-47.32zm-254 126.95a47.37 47.37 0 1 0 47.41 47.37 47.37 47.37 0 0 0-47.41-47.37zm253.91 0a47.37 47.37 0 1 0 47.41 47.37 47.37 47.37 0 0 0-47.32-47.37zM128.5 335.09a47.37 47.37 0 1 0 47.41 47.41 47.37 47.37 0 0 0-47.41-47.41zm253.91 0a47.37 47.37 0 1 0 47.41 47.41 47.37 47.37 0 0 0-47.32-47.41zm102 92.93a56.48 56.48 0 0 1-56.39 56.48h-344a56.48 56.48 0 0 1-56.52-56.48v-344A56.48 56.48 0 0 1 83.98 27.5h344a56.48 56.48 0 0 1 56.52 56.48zm-20-344a36.48 36.48 0 0 0-36.39-36.52h-344A36.48 36.48 0 0 0 47.5 83.98v344a36.48 36.48 0 0 0 36.48 36.52h344a36.48 36.48 0 0 0 36.52-36.48z\"></path></g><!-- react-empty: 6 --></svg>",
+	"static/images/threes.svg": "<svg xmlns=\"http://www.w3.org/2000/svg\" viewBox=\"0 0 512 512\" style=\"height: 512px; width: 512px;\"><path d=\"M0 0h512v512H0z\" fill=\"#ffffff\"></path><g class=\"\" transform=\"translate(0,0)\" style=\"\"><path fill=\"#000000\" d=\"M302.87 255.5a47.37 47.37 0 1 1-47.37-47.37 47.37 47.37 0 0 1 47.37 47.37zM382.5 81.18a47.37 47.37 0 1 0 47.32 47.32 47.37 47.37 0 0 0-47.32-47.32zm-254 253.91a47.37 47.37 0 1 0 47.41 47.41 47.37 47.37 0 0 0-47.41-47.41zm356 92.94a56.48 56.48 0 0 1-56.48 56.47h-344a56.48 56.48 0 0 1-56.52-56.48v-344A56.48 56.48 0 0 1 83.98 27.5h344a56.48 56.48 0 0 1 56.52 56.48zm-20-344a36.48 36.48 0 0 0-36.48-36.53h-344A36.48 36.48 0 0 0 47.5 83.98v344a36.48 36.48 0 0 0 36.48 36.52h344a36.48 36.48 0 0 0 36.52-36.48z\"></path></g><!-- react-empty: 6 --></svg>",
+	"static/images/twos.svg":   "<svg xmlns=\"http://www.w3.org/2000/svg\" viewBox=\"0 0 512 512\" style=\"height: 512px; width: 512px;\"><path d=\"M0 0h512v512H0z\" fill=\"#ffffff\"></path><g class=\"\" transform=\"translate(0,0)\" style=\"\"><path fill=\"#000000\" d=\"M383 81.68A47.37 47.37 0 1 1 335.58 129 47.37 47.37 0 0 1 383 81.68zM81.67 383A47.37 47.37 0 1 0 129 335.59 47.37 47.37 0 0 0 81.67 383zM428 47.57H84A36.48 36.48 0 0 0 47.57 84v344A36.48 36.48 0 0 0 84 464.43h344A36.48 36.48 0 0 0 464.43 428V84A36.48 36.48 0 0 0 428 47.57m0-20A56.54 56.54 0 0 1 484.43 84v344A56.54 56.54 0 0 1 428 484.43H84A56.54 56.54 0 0 1 27.57 428V84A56.54 56.54 0 0 1 84 27.57z\"></path></g><!-- react-empty: 6 --></svg>",
+	"static/js/yahtzee.js":     "/*\n *  Game constants.\n */\n\nlet NUM_TURNS = 13;\nlet NUM_DICE = 5;\n\nlet TURN_BEGIN = 0;\nlet TURN_HOLD1 = 1;\nlet TURN_HOLD2 = 2;\nlet TURN_FILL_BOX = 3;\n\nlet EXPECTED_VALUE = \"expected-value\";\nlet HIGH_SCORE = \"high-score\";\n\nlet YAHTZEE_BONUS = 100;\n\nlet DIE_SIDE_IMAGES = [\n  \"/static/images/blank.svg\",\n  \"/static/images/ones.svg\",\n  \"/static/images/twos.svg\",\n  \"/static/images/threes.svg\",\n  \"/static/images/fours.svg\",\n  \"/static/images/fives.svg\",\n  \"/static/images/sixes.svg\",\n];\n\n/*\n *  Core models for tracking game state.\n */\n\nclass Die {\n  constructor() {\n    this.side = null;\n    this.held = false;\n  }\n}\n\nclass GameState {\n  constructor() {\n    this.boxes = Array(NUM_TURNS).fill(null);\n    this.dice = [];\n    for (var i = 0; i < NUM_DICE; i++) {\n      this.dice.push(new Die());\n    }\n\n    this.yahtzeeBonus = 0;\n\n    this.turn = 0;\n    this.turnState = TURN_BEGIN;\n  }\n\n  get upperHalfScore() {\n    var total = 0;\n    for (var i = 0; i < 6; i++) {\n      if (this.boxes[i] !== null) {\n        total += this.boxes[i];\n      }\n    }\n\n    return total;\n  }\n\n  get upperHalfBonus() {\n    return game.upperHalfScore >= 63 ? 35 : 0;\n  }\n\n  get upperHalfTotal() {\n    return this.upperHalfScore + this.upperHalfBonus;\n  }\n\n  get lowerHalfTotal() {\n    var total = 0;\n    for (var i = 6; i < this.boxes.length; i++) {\n      if (this.boxes[i] !== null) {\n        total += this.boxes[i];\n      }\n    }\n\n    return total;\n  }\n\n  get grandTotal() {\n    return this.upperHalfTotal + this.lowerHalfTotal + this.yahtzeeBonus;\n  }\n\n  get upperHalfFilled() {\n    for (var i = 0; i < 6; i++) {\n      if (this.boxes[i] === null) {\n        return false;\n      }\n    }\n\n    return true;\n  }\n\n  get yahtzeeBonusEligible() {\n    var yahtzeeBox = this.boxes[this.boxes.length - 1];\n    return (yahtzeeBox !== null && yahtzeeBox > 0);\n  }\n\n  get currentRoll() {\n    return this.dice.map((die) => die.side);\n  }\n\n  get heldDice() {\n    return this.dice.filter((die) => die.held).map((die) => die.side).sort();\n  }\n\n  isFilled(box) {\n    return this.boxes[box] !== null;\n  }\n\n  hold(die) {\n    console.log(\"Holding die \" + die);\n    this.dice[die].held = !this.dice[die].held;\n  }\n\n  fill(box) {\n    if (isYahtzee(this.currentRoll) && this.yahtzeeBonusEligible) {\n      console.log(\"Applying Yahtzee joker bonus\");\n      this.yahtzeeBonus += YAHTZEE_BONUS;\n    }\n\n    console.log(\"Filling box \" + box + \" with roll \" + this.currentRoll);\n    $.ajax({\n      type: \"POST\",\n      async: false,\n      dataType: \"json\",\n      data: JSON.stringify({\"box\": box, \"dice\": this.currentRoll}),\n      url: \"/rest/v1/score\",\n      success: (resp) => {\n        this.nextTurn(box, resp.Score);\n      },\n      error: (resp) => {\n        console.log(resp);\n        window.alert(\"Error getting score: \"+resp);\n      }\n    });\n  }\n\n  nextTurn(box, score) {\n    console.log(\"Scored \" + score + \" points\");\n    this.boxes[box] = score;\n    this.turn++;\n    this.turnState = TURN_BEGIN;\n    for (let die of this.dice) {\n      die.side = 0;\n      die.held = false;\n    }\n  }\n\n  roll() {\n    console.log(\"Rolling dice\");\n    for (var i = 0; i < this.dice.length; i++) {\n      var die = this.dice[i];\n      if (!die.held) {\n        console.log(\"Rolling die \" + i);\n        die.side = getRandomInt(1, 6);\n        console.log(\"Die \" + i + \" has new side: \" + die.side);\n      }\n    }\n\n    this.turnState++;\n  }\n}\n\nclass OutcomeCalculator {\n  constructor(game, chart) {\n    this.game = game;\n    this.chart = chart;\n    this.gameType = EXPECTED_VALUE;\n    this.scoreToBeat = 0;\n    this.initChart();\n\n    this.allOptions = null;\n    this.currentTurn = null;\n    this.currentTurnState = null;\n    this.expectedScore = 254;\n  }\n\n  get gameStateRequest() {\n    return {\n      \"GameState\": {\n        \"Filled\": this.game.boxes.map((box) => box !== null),\n        \"YahtzeeBonusEligible\": this.game.yahtzeeBonusEligible,\n        \"UpperHalfScore\": this.game.upperHalfScore,\n      },\n      \"TurnState\": {\n        \"Step\": this.game.turnState,\n        \"Dice\": this.game.currentRoll\n      }\n    };\n  }\n\n  initChart() {\n    this.chart.data = {\n      labels: Array.from({length: 500}, (x, i) => i),\n      datasets: [{\n        label: 'Probability of Reaching Score',\n        steppedLine: true,\n      }]\n    }\n    this.chart.update();\n  }\n\n  setGameType(gameType, scoreToBeat) {\n    this.gameType = gameType;\n    this.scoreToBeat = scoreToBeat;\n  }\n\n  update(callback) {\n    if (this.game.turnState == TURN_BEGIN) {\n      // Can't update outcome distribution until roll.\n      callback();\n      return;\n    }\n\n    if (this.game.turn == this.currentTurn && this.game.turnState == this.currentTurnState) {\n      // Data is already up-to-date.\n      this.setCurrentChoice();\n      callback();\n      return;\n    }\n\n    $.ajax({\n      type: \"POST\",\n      dataType: \"json\",\n      data: JSON.stringify(this.gameStateRequest),\n      url: \"/rest/v1/outcome_distribution\",\n      success: (resp) => {\n        this.onSuccess(resp);\n        callback();\n      },\n      error: (resp) => {\n        console.log(resp);\n        window.alert(\"Error fetching outcome distribution: \"+resp);\n      },\n    });\n  }\n\n  onSuccess(resp) {\n    this.allOptions = resp;\n    this.currentTurn = this.game.turn;\n    this.currentTurnState = this.game.turnState;\n    this.setCurrentChoice();\n  }\n\n  get currentHoldChoice() {\n    // Get the outcome distribution corresponding to the current held dice.\n    let heldDice = this.game.heldDice;\n    for (let choice of this.allOptions.HoldChoices) {\n      if (isArrayEqual(heldDice, choice.HeldDice)) {\n        return choice;\n      }\n    }\n  }\n\n  setCurrentChoice() {\n    if (this.game.turnState < TURN_FILL_BOX) {\n      this.setHoldChoice();\n    } else {\n      this.setFillChoice(this.bestFillChoice);\n    }\n  }\n\n  setHoldChoice() {\n    if (this.allOptions === null || this.allOptions.HoldChoices === null) {\n      return;  // No hold choices when fill must be played.\n    }\n\n    let current = this.currentHoldChoice;\n    this.chart.data.datasets[0].data = shiftDistribution(current.FinalScoreDistribution, this.game.grandTotal);\n    this.expectedScore = current.ExpectedFinalScore;\n  }\n\n  get bestHoldChoice() {\n    var bestChoice = null;\n    var bestScore = 0;\n    for (let choice of outcomes.allOptions.HoldChoices) {\n      let s = this.score(choice);\n      if (s >= bestScore) {\n        bestChoice = choice;\n        bestScore = s;\n      }\n    }\n\n    return bestChoice.HeldDice;\n  }\n\n  get bestFillChoice() {\n    var bestChoice = null;\n    var bestScore = 0;\n    for (let choice of this.allOptions.FillChoices) {\n      let s = this.score(choice);\n      if (s >= bestScore) {\n        bestChoice = choice;\n        bestScore = s;\n      }\n    }\n\n    return bestChoice.BoxFilled;\n  }\n\n  get bestPossibleScore() {\n    var best = 0;\n    if (this.allOptions === null) {\n      return best;\n    }\n\n    if (this.allOptions.HoldChoices !== null) {\n      for (let choice of this.allOptions.HoldChoices) {\n        best = Math.max(this.score(choice), best);\n      }\n    }\n\n    if (this.allOptions.FillChoices !== null) {\n      for (let choice of this.allOptions.FillChoices) {\n        best = Math.max(this.score(choice), best);\n      }\n    }\n\n    return best;\n  }\n\n  score(choice) {\n    if (outcomes.gameType === EXPECTED_VALUE) {\n      return choice.ExpectedFinalScore;\n    } else {\n      return choice.FinalScoreDistribution[outcomes.scoreToBeat];\n    }\n  }\n\n  setFillChoice(box) {\n    if (this.allOptions === null || box === null || this.allOptions.FillChoices === null) {\n      return;\n    }\n\n    var current = null;\n    for (let choice of this.allOptions.FillChoices) {\n      if (choice.BoxFilled === box) {\n        current = choice;\n        break;\n      }\n    }\n\n    this.chart.data.datasets[0].data = shiftDistribution(current.FinalScoreDistribution, this.game.grandTotal);\n    this.expectedScore = current.ExpectedFinalScore;\n  }\n}\n\n// Global state variables representing current game state.\nvar ctx = $(\"#score-distribution\");\nvar chart = new Chart(ctx, {\n  type: 'line',\n  data: {},\n  options: {\n    scales: {\n      yAxes: [{\n        ticks: {\n          beginAtZero: true\n        }\n      }]\n    },\n    elements: {\n      line: {\n        tension: 0, // disables bezier curves\n      }\n    }\n  }\n});\nlet game = new GameState();\nlet outcomes = new OutcomeCalculator(game, chart);\n\n/*\n *  Rendering functions to render the current game state as display.\n */\n\nlet $newGameBtn = $(\"#new-game-btn\");\nlet $gameTypeSelector = $(\"#game-type-selector\");\nlet $highScoreInput = $(\"#high-score-input\");\nlet $quitWarning = $(\"#quit-warning\");\n\nlet $rollBtn = $(\"#roll-btn\");\nlet $spinner = $('<span>Roll <i class=\"fa fa-spinner fa-spin\"></i></span>');\nlet $dice = $(\".die\");\nlet $boxes = $(\".box\");\n\nfunction renderDice() {\n  $dice.each(function(index) {\n    var die = game.dice[index];\n\n    // Set die image to the correct side.\n    var dieImg = DIE_SIDE_IMAGES[die.side];\n    $(this).find(\".die-img\").attr(\"src\", dieImg);\n\n    // Show HELD indicator if die is held.\n    if (die.held) {\n      $(this).find(\".held-indicator\").removeClass(\"invisible\");\n    } else {\n      $(this).find(\".held-indicator\").addClass(\"invisible\");\n    }\n  });\n\n  if (game.turnState < TURN_FILL_BOX) {\n    $rollBtn.text(\"ROLL \" + (game.turnState+1));\n  } else {\n    $rollBtn.text(\"FILL\");\n  }\n\n  var rollEnabled = (game.turn < NUM_TURNS && game.turnState < TURN_FILL_BOX);\n  if (rollEnabled) {\n    $rollBtn.removeClass(\"disabled\");\n  } else {\n    $rollBtn.addClass(\"disabled\");\n  }\n}\n\nfunction renderScoreTable() {\n  // Show score if already filled, else fill button.\n  $boxes.each(function(index) {\n    var $box = $(this);\n    if (game.isFilled(index)) {\n      $box.find(\"button\").addClass(\"invisible\");\n      $box.text(game.boxes[index]);\n    } else {\n      $box.find(\"button\").removeClass(\"invisible\");\n    }\n  });\n\n  // Fill buttons enabled?\n  if (game.turnState == TURN_BEGIN) {\n    $boxes.find(\"button\").addClass(\"disabled\");\n  } else {\n    $boxes.find(\"button\").removeClass(\"disabled\");\n  }\n\n  // Upper-half totals.\n  $(\"#upper-half-score\").text(game.upperHalfScore);\n  $(\"#upper-half-total\").text(game.upperHalfTotal);\n  var bonus = game.upperHalfBonus;\n  if (bonus === 0 && !game.upperHalfFilled) {\n    bonus = \"\";\n  }\n  $(\"#upper-half-bonus\").text(bonus);\n\n  // Lower-half totals.\n  $(\"#yahtzee-bonus\").text(game.yahtzeeBonus);\n  $(\"#lower-half-total\").text(game.lowerHalfTotal);\n  $(\"#grand-total-score\").text(game.grandTotal);\n}\n\nfunction renderBestFill() {\n  var $box = $($boxes[outcomes.bestFillChoice]);\n  $box.find(\".fill-advisor\").removeClass(\"d-none\");\n}\n\nfunction renderAdvice() {\n  $dice.find(\".hold-advisor\").addClass(\"invisible\");\n  $boxes.find(\".fill-advisor\").addClass(\"d-none\");\n\n  if (outcomes.allOptions === null || game.turnState == TURN_BEGIN) {\n    return;\n  }\n\n  if (game.turnState < TURN_FILL_BOX) {\n    let bestChoice = outcomes.bestHoldChoice;\n    if (bestChoice.length == NUM_DICE) {\n      // All dice held, best choice is a fill.\n      renderBestFill();\n    } else {\n      var held = bestChoice.slice();  // Copy\n      $dice.each(function(index) {\n        var die = game.dice[index];\n        var idx = held.indexOf(die.side);\n        if (idx > -1) {\n          held.splice(idx, 1);\n          $(this).find(\".hold-advisor\").removeClass(\"invisible\");\n        } else {\n          $(this).find(\".hold-advisor\").addClass(\"invisible\");\n        }\n      })\n    }\n  } else {\n    renderBestFill();\n  }\n\n  if (outcomes.gameType === HIGH_SCORE && outcomes.bestPossibleScore == 0) {\n    $quitWarning.removeClass(\"d-none\");\n  } else {\n    $quitWarning.addClass(\"d-none\");\n  }\n}\n\nfunction renderOutcomes() {\n  var expectedScore = game.grandTotal + Math.round(outcomes.expectedScore);\n  $(\"#expected-score\").text(expectedScore);\n\n  chart.update();\n}\n\nfunction render() {\n  renderDice();\n  renderScoreTable();\n  renderAdvice();\n  renderOutcomes();\n}\n\n/*\n * Wiring to hook up all the user interaction to the appropriate\n * game state modifications and re-rendering.\n */\n\n// New game button.\n$newGameBtn.click(function() { location.reload() });\n\nfunction updateGameType() {\n  let gameType = $gameTypeSelector.val();\n  let scoreToBeat = $highScoreInput.val();\n  console.log(\"Changing game type to: \" + gameType + \" (score to beat: \" + scoreToBeat + \")\");\n  outcomes.setGameType(gameType, scoreToBeat);\n\n  if (gameType === HIGH_SCORE) {\n    $highScoreInput.removeClass(\"invisible\");\n  } else {\n    $highScoreInput.addClass(\"invisible\");\n  }\n\n  outcomes.update(render);\n}\n\n$gameTypeSelector.change(updateGameType);\n$highScoreInput.change(updateGameType);\n\n// Roll button.\n$rollBtn.click(function() {\n  if (game.turnState == TURN_FILL_BOX) {\n    console.warn(\"Trying to roll dice at an inappropriate time\");\n    return;\n  }\n\n  game.roll();\n  $rollBtn.html($spinner);\n  outcomes.update(render);\n});\n\n// Clicking on each of the dice to toggle held state.\n$dice.find(\"a\").each(function(index) {\n  $(this).click(function() {\n    if (game.turnState == TURN_BEGIN) {\n      console.warn(\"Trying to hold dice before they have been rolled\");\n      return;\n    }\n\n    game.hold(index);\n    outcomes.update(render);\n  });\n});\n\n// Clicking on a box to play the current roll in that box.\n$boxes.find(\"button\").each(function(index) {\n  $(this).click(function() {\n    if (game.turnState == TURN_BEGIN) {\n      console.warn(\"Trying to fill box before dice have been rolled\");\n      return;\n    }\n\n    game.fill(index);\n    outcomes.update(render);\n  });\n\n  $(this).mouseenter(function() {\n    outcomes.setFillChoice(index);\n    render();\n  }).mouseleave(function() {\n    outcomes.setHoldChoice();\n    render();\n  });\n});\n\n/*\n * Helper functions\n */\n\n/**\n * Returns a random integer between min (inclusive) and max (inclusive)\n * Using Math.round() will give you a non-uniform distribution!\n */\nfunction getRandomInt(min, max) {\n    return Math.floor(Math.random() * (max - min + 1)) + min;\n}\n\n/**\n * Check whether the given roll of dice is a Yahtzee.\n */\nfunction isYahtzee(roll) {\n  if (roll === null || roll.length === 0) {\n    return false;\n  }\n\n  var first = roll[0];\n  for (var i = 1; i < roll.length; i++) {\n    if (roll[i] != first) {\n      return false;\n    }\n  }\n\n  return true;\n}\n\nfunction isArrayEqual(arr1, arr2) {\n  return arr1.length === arr2.length &&\n    arr1.every( function(this_i, i) { return this_i == arr2[i] } )\n}\n\nfunction shiftDistribution(arr, n) {\n  return Array(n).fill(1).concat(arr);\n}\n",
+	"templates/error.html":     "<!DOCTYPE html>\n<html lang=\"en\">\n  <head>\n    <meta charset=\"utf-8\">\n    <meta name=\"viewport\" content=\"width=device-width, initial-scale=1, shrink-to-fit=no\">\n\n    <link rel=\"stylesheet\" href=\"https://maxcdn.bootstrapcdn.com/bootstrap/4.0.0/css/bootstrap.min.css\" integrity=\"sha384-Gn5384xqQ1aoWXA+058RXPxPg6fy4IWvTNh0E263XmFcJlSAwiGgFAW/dAiS6JXm\" crossorigin=\"anonymous\">\n    <link rel=\"stylesheet\" href=\"/static/css/yahtzee.css\">\n\n    <title>YAHTZEE - {{.Status}} {{.StatusText}}</title>\n  </head>\n  <body>\n    <div class=\"container\" style=\"margin-top: 40px\">\n      <h1>{{.Status}} {{.StatusText}}</h1>\n      <p>{{.Message}}</p>\n      <a href=\"/\" class=\"btn btn-dark\">Play YAHTZEE</a>\n    </div>\n  </body>\n</html>\n",
+	"templates/index.html":     "<!DOCTYPE html>\n<html lang=\"en\">\n  <head>\n    <meta charset=\"utf-8\">\n    <meta name=\"viewport\" content=\"width=device-width, initial-scale=1, shrink-to-fit=no\">\n\n    <link rel=\"stylesheet\" href=\"https://maxcdn.bootstrapcdn.com/bootstrap/4.0.0/css/bootstrap.min.css\" integrity=\"sha384-Gn5384xqQ1aoWXA+058RXPxPg6fy4IWvTNh0E263XmFcJlSAwiGgFAW/dAiS6JXm\" crossorigin=\"anonymous\">\n    <link rel=\"stylesheet\" href=\"https://use.fontawesome.com/releases/v5.0.6/css/all.css\">\n    <link rel=\"stylesheet\" href=\"/static/css/yahtzee.css\">\n\n    <script src=\"https://code.jquery.com/jquery-3.3.1.min.js\" integrity=\"sha256-FgpCb/KJQlLNfOu91ta32o/NMZxltwRo8QtmkMRdAu8=\" crossorigin=\"anonymous\"></script>\n    <script src=\"https://cdnjs.cloudflare.com/ajax/libs/popper.js/1.12.9/umd/popper.min.js\" integrity=\"sha384-ApNbgh9B+Y1QKtv3Rn7W3mgPxhU9K/ScQsAP7hUibX39j7fakFPskvXusvfa0b4Q\" crossorigin=\"anonymous\"></script>\n    <script src=\"https://maxcdn.bootstrapcdn.com/bootstrap/4.0.0/js/bootstrap.min.js\" integrity=\"sha384-JZR6Spejh4U02d8jOt6vLEHfe/JQGiRRSQQxSfFWpi1MquVdAyjUar5+76PVCmYl\" crossorigin=\"anonymous\"></script>\n    <script src=\"https://cdnjs.cloudflare.com/ajax/libs/Chart.js/2.6.0/Chart.min.js\"></script>\n\n    <title>YAHTZEE</title>\n  </head>\n  <body>\n    <div class=\"container-fluid\" style=\"margin-top: 10px\">\n      <div class=\"row\">\n        <div class=\"col\">\n          <table id=\"score-table\" class=\"table table-sm table-bordered\">\n            <colgroup>\n              <col style=\"width: 75%\">\n              <col style=\"width: 25%\">\n            </colgroup>\n            <tbody id=\"upper-half\">\n              <tr>\n                <th>Upper Section</th>\n              </tr>\n              <tr>\n                <th scope=\"row\">Aces <img src=\"/static/images/ones.svg\" height=\"20\"/> = 1</th>\n                <td id=\"ones-score\" class=\"box\">\n                  <button class=\"fill-btn btn btn-sm disabled\">Fill</button>\n                  <span class=\"fill-advisor d-none\"><i class=\"fas fa-asterisk text-primary\"></i></span>\n                </td>\n              </tr>\n              <tr>\n                <th scope=\"row\">Twos <img src=\"/static/images/twos.svg\" height=\"20\"/> = 2</th>\n                <td id=\"twos-score\" class=\"box\">\n                  <button class=\"fill-btn btn btn-sm disabled\">Fill</button>\n                  <span class=\"fill-advisor d-none\"><i class=\"fas fa-asterisk text-primary\"></i></span>\n                </td>\n              </tr>\n              <tr>\n                <th scope=\"row\">Threes <img src=\"/static/images/threes.svg\" height=\"20\"/> = 3</th>\n                <td id=\"threes-score\" class=\"box\">\n                  <button class=\"fill-btn btn btn-sm disabled\">Fill</button>\n                  <span class=\"fill-advisor d-none\"><i class=\"fas fa-asterisk text-primary\"></i></span>\n                </td>\n              </tr>\n              <tr>\n                <th scope=\"row\">Fours <img src=\"/static/images/fours.svg\" height=\"20\"/> = 4</th>\n                <td id=\"fours-score\" class=\"box\">\n                  <button class=\"fill-btn btn btn-sm disabled\">Fill</button>\n                  <span class=\"fill-advisor d-none\"><i class=\"fas fa-asterisk text-primary\"></i></span>\n                </td>\n              </tr>\n              <tr>\n                <th scope=\"row\">Fives <img src=\"/static/images/fives.svg\" height=\"20\"/> = 5</th>\n                <td id=\"fives-score\" class=\"box\">\n                  <button class=\"fill-btn btn btn-sm disabled\">Fill</button>\n                  <span class=\"fill-advisor d-none\"><i class=\"fas fa-asterisk text-primary\"></i></span>\n                </td>\n              </tr>\n              <tr>\n                <th scope=\"row\">Sixes <img src=\"/static/images/sixes.svg\" height=\"20\"/> = 6</th>\n                <td id=\"sixes-score\" class=\"box\">\n                  <button class=\"fill-btn btn btn-sm disabled\">Fill</button>\n                  <span class=\"fill-advisor d-none\"><i class=\"fas fa-asterisk text-primary\"></i></span>\n                </td>\n              </tr>\n              <tr>\n                <th scope=\"row\">TOTAL SCORE</th>\n                <td id=\"upper-half-score\" class=\"score-box\">0</td>\n              </tr>\n              <tr>\n                <th scope=\"row\">Bonus <small>if total score is 63 or over</small></th>\n                <td id=\"upper-half-bonus\" class=\"score-box\"></td>\n              </tr>\n              <tr>\n                <th scope=\"row\">TOTAL <small>Of Upper Section</small></th>\n                <td id=\"upper-half-total\" class=\"score-box\">0</td>\n              </tr>\n            </tbody>\n            <tbody id=\"lower-half\">\n              <tr>\n                <th scope=\"row\">Lower Section</th>\n              </tr>\n              <tr>\n                <th scope=\"row\">3 of a kind</th>\n                <td id=\"three-of-a-kind-score\" class=\"box\">\n                  <button class=\"fill-btn btn btn-sm disabled\">Fill</button>\n                  <span class=\"fill-advisor d-none\"><i class=\"fas fa-asterisk text-primary\"></i></span>\n                </td>\n              </tr>\n              <tr>\n                <th scope=\"row\">4 of a kind</th>\n                <td id=\"four-of-a-kind-score\" class=\"box\">\n                  <button class=\"fill-btn btn btn-sm disabled\">Fill</button>\n                  <span class=\"fill-advisor d-none\"><i class=\"fas fa-asterisk text-primary\"></i></span>\n                </td>\n              </tr>\n              <tr>\n                <th scope=\"row\">Full House</th>\n                <td id=\"full-house-score\" class=\"box\">\n                  <button class=\"fill-btn btn btn-sm disabled\">Fill</button>\n                  <span class=\"fill-advisor d-none\"><i class=\"fas fa-asterisk text-primary\"></i></span>\n                </td>\n              </tr>\n              <tr>\n                <th scope=\"row\">Sm. Straight <small>Sequence of 4</small></th>\n                <td id=\"small-straight-score\" class=\"box\">\n                  <button class=\"fill-btn btn btn-sm disabled\">Fill</button>\n                  <span class=\"fill-advisor d-none\"><i class=\"fas fa-asterisk text-primary\"></i></span>\n                </td>\n              </tr>\n              <tr>\n                <th scope=\"row\">Lg. Straight <small>Sequence of 5</small></th>\n                <td id=\"large-straight-score\" class=\"box\">\n                  <button class=\"fill-btn btn btn-sm disabled\">Fill</button>\n                  <span class=\"fill-advisor d-none\"><i class=\"fas fa-asterisk text-primary\"></i></span>\n                </td>\n              </tr>\n              <tr>\n                <th scope=\"row\">Chance</th>\n                <td id=\"chance-score\" class=\"box\">\n                  <button class=\"fill-btn btn btn-sm disabled\">Fill</button>\n                  <span class=\"fill-advisor d-none\"><i class=\"fas fa-asterisk text-primary\"></i></span>\n                </td>\n              </tr>\n              <tr>\n                <th scope=\"row\">YAHTZEE <small>5 of a kind</small></th>\n                <td id=\"yahtzee-score\" class=\"box\">\n                  <button class=\"fill-btn btn btn-sm disabled\">Fill</button>\n                  <span class=\"fill-advisor d-none\"><i class=\"fas fa-asterisk text-primary\"></i></span>\n                </td>\n              </tr>\n              <tr>\n                <th scope=\"row\">YAHTZEE BONUS</th>\n                <td id=\"yahtzee-bonus\" class=\"score-box\">0</td>\n              </tr>\n            </tbody>\n            <tfoot>\n              <tr>\n                <th>TOTAL <small>Of Lower Section</small></th>\n                <td id=\"lower-half-total\" class=\"score-box\">0</td>\n              </tr>\n              <tr>\n                <th>GRAND TOTAL</th>\n                <td id=\"grand-total-score\" class=\"score-box\">0</td>\n              </tr>\n            </tfoot>\n          </table>\n        </div>\n        <div class=\"col\">\n          <canvas id=\"score-distribution\"></canvas>\n          <div class=\"expected-score-container text-center\">Expected score = <span id=\"expected-score\">254</span></div>\n\n          <div class=\"dice-row row-fluid\">\n            <div class=\"die\">\n              <div class=\"hold-advisor invisible\"><i class=\"fas fa-asterisk text-primary\"></i></div>\n              <a href=\"#hold1\"><img id=\"die1\" class=\"die-img\" src=\"/static/images/blank.svg\" height=\"60px\"/></a></br>\n              <span id=\"die1-held\" class=\"held-indicator invisible\">HELD</span>\n            </div>\n            <div class=\"die\">\n              <div class=\"hold-advisor invisible\"><i class=\"fas fa-asterisk text-primary\"></i></div>\n              <a href=\"#hold2\"><img id=\"die2\" class=\"die-img\" src=\"/static/images/blank.svg\" height=\"60px\"/></a></br>\n              <span id=\"die2-held\" class=\"held-indicator invisible\">HELD</span>\n            </div>\n            <div class=\"die\">\n              <div class=\"hold-advisor invisible\"><i class=\"fas fa-asterisk text-primary\"></i></div>\n              <a href=\"#hold3\"><img id=\"die3\" class=\"die-img\" src=\"/static/images/blank.svg\" height=\"60px\"/></a></br>\n              <span id=\"die3-held\" class=\"held-indicator invisible\">HELD</span>\n            </div>\n            <div class=\"die\">\n              <div class=\"hold-advisor invisible\"><i class=\"fas fa-asterisk text-primary\"></i></div>\n              <a href=\"#hold4\"><img id=\"die4\" class=\"die-img\" src=\"/static/images/blank.svg\" height=\"60px\"/></a></br>\n              <span id=\"die4-held\" class=\"held-indicator invisible\">HELD</span>\n            </div>\n            <div class=\"die\">\n              <div class=\"hold-advisor invisible\"><i class=\"fas fa-asterisk text-primary\"></i></div>\n              <a href=\"#hold5\"><img id=\"die5\" class=\"die-img\" src=\"/static/images/blank.svg\" height=\"60px\"/></a></br>\n              <span id=\"die5-held\" class=\"held-indicator invisible\">HELD</span>\n            </div>\n\n            <button id=\"roll-btn\" class=\"btn btn-dark btn-lg roll-btn\">ROLL 1</button>\n          </div>\n\n          <div class=\"new-game-container input-group\">\n            <button id=\"new-game-btn\" class=\"btn btn-danger\">New Game</button>\n            <select class=\"form-control\" id=\"game-type-selector\">\n              <option value=\"expected-value\">Max Expected Value</option>\n              <option value=\"high-score\">Beat High Score</option>\n            </select>\n            <input id=\"high-score-input\" type=\"number\" class=\"invisible\" value=\"200\" min=\"0\" step=\"10\">\n          </div>\n\n          <div id=\"quit-warning\" class=\"alert alert-danger d-none\" role=\"alert\">\n            No chance of achieving desired high score!\n          </div>\n\n          <div id=\"instructions\">\n            Click dice to hold them. <br/>\n            <i class=\"fas fa-asterisk text-primary\"></i> = optimal choice\n          </div>\n        </div>\n      </div>\n    </div>\n\n    <script src=\"/static/js/yahtzee.js\"></script>\n  </body>\n</html>\n",
+}
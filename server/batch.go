@@ -0,0 +1,324 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"runtime"
+
+	"github.com/golang/glog"
+)
+
+const (
+	// MaxBatchSize is the maximum number of items in a single batch request.
+	MaxBatchSize = 100000
+	// MaxBatchItemBytes is the maximum size of a single item in a
+	// batch request, including whitespace.
+	MaxBatchItemBytes = 64 << 10
+)
+
+// batchJob is an item of a batch request to evaluate.
+type batchJob struct {
+	index int
+	// raw is the JSON of the item, unless err is set
+	// because the item could not be read.
+	raw    []byte
+	err    error
+	result chan BatchResult
+}
+
+// BatchOptimalMove evaluates the optimal move for many positions in
+// parallel. See BatchOptimalMoveRequest for the request and response
+// formats. The whole batch is read before any results are written,
+// since the request body cannot be read once the response is flushed.
+func (ys *YahtzeeServer) BatchOptimalMove(w http.ResponseWriter, r *http.Request) {
+	items, err := readBatch(r.Body)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	nWorkers := runtime.NumCPU()
+	jobs := make(chan batchJob, nWorkers)
+	// results are the pending results, in the order of the items. The
+	// buffer bounds the number of items evaluated ahead of the results written.
+	results := make(chan chan BatchResult, 4*nWorkers)
+	// done is closed when the handler returns (e.g. if the client goes
+	// away before the batch is complete), to stop evaluating items.
+	done := make(chan struct{})
+	defer close(done)
+
+	for i := 0; i < nWorkers; i++ {
+		go func() {
+			for job := range jobs {
+				job.result <- ys.evaluateBatchItem(job)
+			}
+		}()
+	}
+
+	go func() {
+		defer close(results)
+		defer close(jobs)
+		for _, job := range items {
+			job.result = make(chan BatchResult, 1)
+			select {
+			case results <- job.result:
+			case <-done:
+				return
+			}
+
+			select {
+			case jobs <- job:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	w.Header().Set("Content-Type", "application/x-ndjson; charset=UTF-8")
+	w.WriteHeader(http.StatusOK)
+	flusher, _ := w.(http.Flusher)
+	enc := json.NewEncoder(w)
+	for result := range results {
+		if err := enc.Encode(<-result); err != nil {
+			glog.Warning(err)
+			return
+		}
+
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+}
+
+// readBatch reads every item of a batch request. It returns an error
+// if the batch cannot be read, or has more than MaxBatchSize items.
+func readBatch(r io.Reader) ([]batchJob, error) {
+	var items []batchJob
+	br := newBatchReader(r)
+	for {
+		raw, itemErr, err := br.next()
+		if err == io.EOF {
+			return items, nil
+		} else if err != nil {
+			return nil, err
+		} else if len(items) >= MaxBatchSize {
+			return nil, badRequest(ErrCodeInvalidBatch, "",
+				"batch exceeds maximum size of %d items", MaxBatchSize)
+		}
+
+		items = append(items, batchJob{index: len(items), raw: raw, err: itemErr})
+	}
+}
+
+func (ys *YahtzeeServer) evaluateBatchItem(job batchJob) BatchResult {
+	result := BatchResult{Index: job.index}
+	if job.err != nil {
+		result.Error = reportError(job.err)
+		return result
+	}
+
+	req := &OptimalMoveRequest{}
+	if err := json.Unmarshal(job.raw, req); err != nil {
+		result.Error = reportError(invalidJSON(err))
+		return result
+	}
+
+	resp, err := ys.ComputeOptimalMove(req)
+	if err != nil {
+		result.Error = reportError(err)
+		return result
+	}
+
+	result.Response = resp
+	return result
+}
+
+// batchReader reads the items of a batch request one at a time. The
+// request may be either a JSON array or JSON Lines. The elements of an
+// array are split without decoding them, so that a syntax error in one
+// item does not prevent reading the rest.
+type batchReader struct {
+	br      *bufio.Reader
+	started bool
+	array   bool
+	// n is the number of items read.
+	n int
+	// end is set once the end of the array has been read.
+	end bool
+}
+
+func newBatchReader(r io.Reader) *batchReader {
+	return &batchReader{br: bufio.NewReader(r)}
+}
+
+// next returns the JSON of the next item. If only the item could not
+// be read (e.g. because it is too large), itemErr is set and reading
+// may continue. Otherwise err is set, and is io.EOF after the last item.
+func (b *batchReader) next() (raw []byte, itemErr, err error) {
+	if !b.started {
+		b.started = true
+		first, err := peekNonSpace(b.br)
+		if err == io.EOF {
+			return nil, nil, io.EOF
+		} else if err != nil {
+			return nil, nil, invalidJSON(err)
+		} else if first == '[' {
+			b.br.ReadByte()
+			b.array = true
+		}
+	}
+
+	if b.array {
+		raw, itemErr, err = b.nextElement()
+	} else {
+		raw, itemErr, err = b.nextLine()
+	}
+	if err == nil {
+		b.n++
+	}
+
+	return raw, itemErr, err
+}
+
+// nextLine returns the next non-empty line of JSON Lines.
+func (b *batchReader) nextLine() ([]byte, error, error) {
+	for {
+		var line []byte
+		tooLarge := false
+		for {
+			chunk, err := b.br.ReadSlice('\n')
+			if len(line)+len(chunk) > MaxBatchItemBytes {
+				// Discard the rest of the line.
+				tooLarge, line = true, nil
+			} else if !tooLarge {
+				line = append(line, chunk...)
+			}
+
+			if err == bufio.ErrBufferFull {
+				continue
+			} else if err == io.EOF && !tooLarge && len(bytes.TrimSpace(line)) == 0 {
+				return nil, nil, io.EOF
+			} else if err != nil && err != io.EOF {
+				return nil, nil, invalidJSON(err)
+			}
+			break
+		}
+
+		if tooLarge {
+			return nil, itemTooLarge(), nil
+		} else if line = bytes.TrimSpace(line); len(line) > 0 {
+			return line, nil, nil
+		}
+	}
+}
+
+// nextElement returns the next element of a JSON array, which ends
+// at the following comma or closing bracket outside of any string,
+// object or array.
+func (b *batchReader) nextElement() ([]byte, error, error) {
+	if b.end {
+		return nil, nil, io.EOF
+	}
+
+	var raw []byte
+	var depth int
+	tooLarge, inString, escaped := false, false, false
+	for {
+		c, err := b.br.ReadByte()
+		if err == io.EOF {
+			return nil, nil, badRequest(ErrCodeInvalidJSON, "", "invalid JSON: unexpected end of batch")
+		} else if err != nil {
+			return nil, nil, invalidJSON(err)
+		}
+
+		if inString {
+			if escaped {
+				escaped = false
+			} else if c == '\\' {
+				escaped = true
+			} else if c == '"' {
+				inString = false
+			}
+		} else if depth == 0 && (c == ',' || c == ']') {
+			b.end = c == ']'
+			break
+		} else {
+			switch c {
+			case '"':
+				inString = true
+			case '{', '[':
+				depth++
+			case '}', ']':
+				if depth == 0 {
+					return nil, nil, badRequest(ErrCodeInvalidJSON, "",
+						"invalid JSON: unexpected %q in batch", c)
+				}
+				depth--
+			}
+		}
+
+		if len(raw) >= MaxBatchItemBytes {
+			tooLarge, raw = true, nil
+		} else if !tooLarge {
+			raw = append(raw, c)
+		}
+	}
+
+	if b.end {
+		if err := b.checkTrailingData(); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	raw = bytes.TrimSpace(raw)
+	if tooLarge {
+		return nil, itemTooLarge(), nil
+	} else if len(raw) == 0 && b.end {
+		if b.n > 0 {
+			return nil, nil, badRequest(ErrCodeInvalidJSON, "", "invalid JSON: trailing comma in batch")
+		}
+		return nil, nil, io.EOF
+	} else if len(raw) == 0 {
+		return nil, badRequest(ErrCodeInvalidJSON, "", "invalid JSON: empty item in batch"), nil
+	}
+
+	return raw, nil, nil
+}
+
+// checkTrailingData returns an error if there is anything other than
+// whitespace after the end of the array.
+func (b *batchReader) checkTrailingData() error {
+	if _, err := peekNonSpace(b.br); err == io.EOF {
+		return nil
+	} else if err != nil {
+		return invalidJSON(err)
+	}
+
+	return badRequest(ErrCodeInvalidJSON, "", "invalid JSON: unexpected data after the end of the batch")
+}
+
+func itemTooLarge() error {
+	return badRequest(ErrCodeInvalidBatch, "",
+		"batch item exceeds maximum size of %d bytes", MaxBatchItemBytes)
+}
+
+// peekNonSpace skips leading whitespace and returns the next byte
+// without consuming it.
+func peekNonSpace(br *bufio.Reader) (byte, error) {
+	for {
+		b, err := br.Peek(1)
+		if err != nil {
+			return 0, err
+		}
+
+		switch b[0] {
+		case ' ', '\t', '\r', '\n':
+			br.ReadByte()
+		default:
+			return b[0], nil
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// postBatch posts body to the batch endpoint.
+func postBatch(ys *YahtzeeServer, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("POST", "/rest/v1/batch/optimal_move", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	ys.BatchOptimalMove(w, req)
+	return w
+}
+
+// runBatch posts body to the batch endpoint, and returns the results.
+func runBatch(t *testing.T, ys *YahtzeeServer, body string) []BatchResult {
+	w := postBatch(ys, body)
+	if w.Code != 200 {
+		t.Fatalf("status = %d, expected 200: %v", w.Code, w.Body)
+	}
+
+	return readResults(t, w.Body)
+}
+
+func readResults(t *testing.T, r io.Reader) []BatchResult {
+	var results []BatchResult
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		var result BatchResult
+		if err := json.Unmarshal(scanner.Bytes(), &result); err != nil {
+			t.Fatalf("invalid result %q: %v", scanner.Text(), err)
+		}
+		results = append(results, result)
+	}
+
+	return results
+}
+
+// errorCodes returns the error code of each result ("" if it succeeded).
+func errorCodes(results []BatchResult) []string {
+	codes := make([]string, len(results))
+	for i, result := range results {
+		if result.Error != nil {
+			codes[i] = result.Error.Code
+		}
+	}
+
+	return codes
+}
+
+func lateGameMove(dice string) string {
+	return `{"GameState":` + lateGame + `,"TurnState":{"Step":1,"Dice":` + dice + `}}`
+}
+
+func TestBatchOrdering(t *testing.T) {
+	ys := newTestServer()
+	dice := []string{"[1,2,3,4,5]", "[6,6,6,6,6]", "[1,1,2,2,3]", "[2,3,4,5,6]", "[5,5,5,1,2]"}
+	var lines []string
+	for _, d := range dice {
+		lines = append(lines, lateGameMove(d), "")
+	}
+
+	results := runBatch(t, ys, strings.Join(lines, "\n"))
+	if len(results) != len(dice) {
+		t.Fatalf("got %d results, expected %d", len(results), len(dice))
+	}
+
+	for i, result := range results {
+		if result.Index != i {
+			t.Errorf("result %d has Index %d", i, result.Index)
+		}
+
+		req := &OptimalMoveRequest{}
+		if err := json.Unmarshal([]byte(lateGameMove(dice[i])), req); err != nil {
+			t.Fatal(err)
+		}
+		expected, err := ys.ComputeOptimalMove(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// The held dice may differ between equally good holds.
+		if result.Response == nil || result.Response.Value != expected.Value {
+			t.Errorf("result %d = %+v, expected %+v", i, result.Response, expected)
+		}
+	}
+}
+
+func TestBatchItemErrors(t *testing.T) {
+	ys := newTestServer()
+	items := []string{
+		lateGameMove("[1,2,3,4,5]"),
+		`{"GameState": }`,
+		lateGameMove("[1,2,3]"),
+		`{"TurnState": {"Dice": "[1,2]}"}}`,
+		lateGameMove("[6,6,6,6,6]"),
+	}
+	expected := []string{"", ErrCodeInvalidJSON, ErrCodeInvalidDice, ErrCodeInvalidJSON, ""}
+
+	// An error in one item does not affect the others, in either format.
+	for _, body := range []string{
+		"[" + strings.Join(items, ",") + "]",
+		strings.Join(items, "\n"),
+	} {
+		if codes := errorCodes(runBatch(t, ys, body)); !reflect.DeepEqual(codes, expected) {
+			t.Errorf("%v: error codes = %q, expected %q", body, codes, expected)
+		}
+	}
+}
+
+func TestBatchLimits(t *testing.T) {
+	ys := newTestServer()
+	tooLarge := `{"GameState":` + strings.Repeat(" ", MaxBatchItemBytes) + `{}}`
+	valid := lateGameMove("[6,6,6,6,6]")
+	cases := []struct {
+		name     string
+		body     string
+		expected []string
+	}{
+		{"empty", "", []string{}},
+		{"empty array", " [ ] ", []string{}},
+		{"array item too large", "[" + tooLarge + "," + valid + "]",
+			[]string{ErrCodeInvalidBatch, ""}},
+		{"line too large", tooLarge + "\n" + valid,
+			[]string{ErrCodeInvalidBatch, ""}},
+	}
+
+	for _, tc := range cases {
+		codes := errorCodes(runBatch(t, ys, tc.body))
+		if !reflect.DeepEqual(codes, tc.expected) {
+			t.Errorf("%v: error codes = %q, expected %q", tc.name, codes, tc.expected)
+		}
+	}
+}
+
+func TestBatchInvalid(t *testing.T) {
+	ys := newTestServer()
+	valid := lateGameMove("[6,6,6,6,6]")
+	cases := []struct {
+		name     string
+		body     string
+		expected string
+	}{
+		{"trailing data", "[" + valid + "] {}", ErrCodeInvalidJSON},
+		{"trailing comma", "[" + valid + ",]", ErrCodeInvalidJSON},
+		{"unterminated array", "[" + valid + "," + valid, ErrCodeInvalidJSON},
+		{"unbalanced array", "[" + valid + "}," + valid + "]", ErrCodeInvalidJSON},
+		// Items that are not valid JSON are counted without evaluating them.
+		{"too many items", strings.Repeat("x\n", MaxBatchSize+10), ErrCodeInvalidBatch},
+	}
+
+	for _, tc := range cases {
+		w := postBatch(ys, tc.body)
+		var resp ErrorResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Errorf("%v: invalid error response %q: %v", tc.name, w.Body, err)
+		} else if w.Code != 400 || resp.Error.Code != tc.expected {
+			t.Errorf("%v: status = %d, error = %+v, expected 400 %v",
+				tc.name, w.Code, resp.Error, tc.expected)
+		}
+	}
+}
+
+func TestBatchOverHTTP(t *testing.T) {
+	ys := newTestServer()
+	srv := httptest.NewServer(newTestMux(ys))
+	defer srv.Close()
+
+	// The results are flushed while they are written, which must not
+	// prevent the rest of the batch from being read. net/http closes
+	// the request body when the response starts if less than 256 KB
+	// of it remain, so the batch is smaller than that.
+	const n = 1200
+	var body bytes.Buffer
+	for i := 0; i < n; i++ {
+		dice := fmt.Sprintf("[%d,%d,%d,%d,%d]", 1+i%6, 1+i/6%6, 1+i/36%6, 6, 6)
+		body.WriteString(lateGameMove(dice) + "\n")
+	}
+	if body.Len() >= 256<<10 {
+		t.Fatalf("batch is %d bytes, expected less than 256 KB", body.Len())
+	}
+
+	resp, err := http.Post(srv.URL+"/rest/v1/batch/optimal_move", "application/x-ndjson", &body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	results := readResults(t, resp.Body)
+	if len(results) != n {
+		t.Fatalf("got %d results, expected %d", len(results), n)
+	}
+	for i, result := range results {
+		if result.Index != i || result.Error != nil {
+			t.Errorf("result %d = %+v, expected Index %d and no error", i, result, i)
+		}
+	}
+}
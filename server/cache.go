@@ -0,0 +1,204 @@
+package server
+
+import (
+	"container/list"
+	"sync"
+
+	"github.com/timpalpant/yahtzee"
+	"github.com/timpalpant/yahtzee/optimization"
+)
+
+// DefaultResultCacheBytes is the default limit on the (estimated)
+// memory used by the turn outcomes kept in the result cache.
+const DefaultResultCacheBytes = 64 << 20
+
+const (
+	// entryOverhead is the estimated memory used by an entry in the
+	// cache, in addition to its outcomes.
+	entryOverhead = 128
+	// outcomeOverhead is the estimated memory used by each outcome
+	// in an entry, in addition to its GameResult.
+	outcomeOverhead = 48
+)
+
+// outcomeKey identifies the outcomes of the choices available at one
+// step of a turn. The table determines the objective being optimized,
+// and the generation identifies the version of the tables, so that
+// results computed with a table that has since been reloaded are
+// never returned.
+type outcomeKey struct {
+	table      string
+	generation uint64
+	game       yahtzee.GameState
+	step       yahtzee.TurnStep
+	roll       yahtzee.Roll
+}
+
+type cacheEntry struct {
+	key   outcomeKey
+	value interface{}
+	// size is the estimated memory used by the entry, in bytes.
+	size int64
+}
+
+// resultCache is a concurrency-safe LRU cache of turn outcomes,
+// so that repeated requests for the same position do not need to
+// recompute hold expectations from scratch. The cache is limited by the
+// memory used by the outcomes, since a score distribution or expected
+// work outcome is ~6 KB, and an entry may hold dozens of them.
+type resultCache struct {
+	mu       sync.Mutex
+	maxBytes int64
+	bytes    int64
+	// generation of the current tables. Results computed with
+	// previous generations are not added.
+	generation uint64
+	entries    map[outcomeKey]*list.Element
+	// lru orders the entries from most to least recently used.
+	lru *list.List
+}
+
+func newResultCache(maxBytes int64) *resultCache {
+	return &resultCache{
+		maxBytes: maxBytes,
+		entries:  make(map[outcomeKey]*list.Element),
+		lru:      list.New(),
+	}
+}
+
+func (c *resultCache) get(key outcomeKey) (interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		resultCacheRequests.Inc("miss")
+		return nil, false
+	}
+
+	resultCacheRequests.Inc("hit")
+	c.lru.MoveToFront(elem)
+	return elem.Value.(*cacheEntry).value, true
+}
+
+// add caches the value, which uses an estimated size bytes of memory.
+func (c *resultCache) add(key outcomeKey, value interface{}, size int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if key.generation != c.generation {
+		// The tables were reloaded while the outcomes were computed.
+		return
+	} else if elem, ok := c.entries[key]; ok {
+		// Another request computed the same outcomes concurrently.
+		c.lru.MoveToFront(elem)
+		return
+	}
+
+	size += entryOverhead
+	c.entries[key] = c.lru.PushFront(&cacheEntry{key, value, size})
+	c.bytes += size
+	c.evict()
+}
+
+// setMaxBytes changes the limit on the memory used by the cache,
+// evicting the least recently used entries if necessary.
+// If maxBytes is 0, results are not cached.
+func (c *resultCache) setMaxBytes(maxBytes int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.maxBytes = maxBytes
+	c.evict()
+}
+
+// clear removes all entries, when the tables are replaced by the
+// given generation.
+func (c *resultCache) clear(generation uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.generation = generation
+	c.entries = make(map[outcomeKey]*list.Element)
+	c.lru.Init()
+	c.bytes = 0
+	resultCacheEntries.Set(0)
+	resultCacheBytes.Set(0)
+}
+
+func (c *resultCache) evict() {
+	for c.bytes > c.maxBytes {
+		elem := c.lru.Back()
+		entry := elem.Value.(*cacheEntry)
+		c.lru.Remove(elem)
+		delete(c.entries, entry.key)
+		c.bytes -= entry.size
+		resultCacheEvictions.Inc()
+	}
+
+	resultCacheEntries.Set(float64(c.lru.Len()))
+	resultCacheBytes.Set(float64(c.bytes))
+}
+
+// resultSize estimates the memory used by a GameResult, in bytes.
+func resultSize(result optimization.GameResult) int64 {
+	switch r := result.(type) {
+	case optimization.ScoreDistribution:
+		return int64(4 * len(r))
+	case optimization.ExpectedWork:
+		return int64(4*len(r.Values)) + 32
+	}
+
+	return 4
+}
+
+// holdOutcomes returns the outcome of each possible hold of the roll
+// at the given step (Hold1 or Hold2), with the named table.
+// The results are shared and must not be modified.
+func (t tables) holdOutcomes(table string, game yahtzee.GameState,
+	step yahtzee.TurnStep, roll yahtzee.Roll) map[yahtzee.Roll]optimization.GameResult {
+	key := outcomeKey{table, t.generation, game, step, roll}
+	if outcomes, ok := t.results.get(key); ok {
+		return outcomes.(map[yahtzee.Roll]optimization.GameResult)
+	}
+
+	opt := optimization.NewTurnOptimizer(t.get(table), game)
+	defer opt.Close()
+	var outcomes map[yahtzee.Roll]optimization.GameResult
+	if step == yahtzee.Hold1 {
+		outcomes = opt.GetHold1Outcomes(roll)
+	} else {
+		outcomes = opt.GetHold2Outcomes(roll)
+	}
+
+	// The outcomes belong to the optimizer's caches, which are
+	// reused once it is closed.
+	var size int64
+	for held, outcome := range outcomes {
+		outcomes[held] = outcome.Copy()
+		size += outcomeOverhead + resultSize(outcome)
+	}
+
+	t.results.add(key, outcomes, size)
+	return outcomes
+}
+
+// fillOutcomes returns the outcome of filling each available box
+// with the roll, with the named table.
+// The results are shared and must not be modified.
+func (t tables) fillOutcomes(table string, game yahtzee.GameState,
+	roll yahtzee.Roll) map[yahtzee.Box]optimization.GameResult {
+	key := outcomeKey{table, t.generation, game, yahtzee.FillBox, roll}
+	if outcomes, ok := t.results.get(key); ok {
+		return outcomes.(map[yahtzee.Box]optimization.GameResult)
+	}
+
+	opt := optimization.NewTurnOptimizer(t.get(table), game)
+	defer opt.Close()
+	outcomes := opt.GetFillOutcomes(roll)
+	var size int64
+	for _, outcome := range outcomes {
+		size += outcomeOverhead + resultSize(outcome)
+	}
+
+	t.results.add(key, outcomes, size)
+	return outcomes
+}
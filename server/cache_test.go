@@ -0,0 +1,96 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/timpalpant/yahtzee"
+	"github.com/timpalpant/yahtzee/optimization"
+)
+
+func TestResultCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	c := newResultCache(2 * entryOverhead)
+	keys := make([]outcomeKey, 3)
+	for i := range keys {
+		keys[i] = outcomeKey{game: yahtzee.GameState(i), step: yahtzee.Hold1}
+	}
+
+	c.add(keys[0], 0, 0)
+	c.add(keys[1], 1, 0)
+	if _, ok := c.get(keys[0]); !ok {
+		t.Fatal("expected keys[0] to be cached")
+	}
+	c.add(keys[2], 2, 0)
+
+	if _, ok := c.get(keys[1]); ok {
+		t.Error("expected least recently used keys[1] to be evicted")
+	}
+	for _, i := range []int{0, 2} {
+		if v, ok := c.get(keys[i]); !ok || v.(int) != i {
+			t.Errorf("get(keys[%d]) = %v, %v; expected %d, true", i, v, ok, i)
+		}
+	}
+
+	c.setMaxBytes(0)
+	if _, ok := c.get(keys[0]); ok {
+		t.Error("expected cache to be empty after setMaxBytes(0)")
+	}
+}
+
+func TestResultCacheLimitsBytes(t *testing.T) {
+	c := newResultCache(10000)
+	small := outcomeKey{step: yahtzee.Hold1}
+	large := outcomeKey{step: yahtzee.Hold2}
+	c.add(small, 0, 1000)
+	c.add(large, 1, 8000)
+	if c.bytes != 9000+2*entryOverhead {
+		t.Errorf("bytes = %d, expected %d", c.bytes, 9000+2*entryOverhead)
+	}
+
+	// Adding another large entry evicts both of the others.
+	c.add(outcomeKey{step: yahtzee.FillBox}, 2, 8000)
+	if _, ok := c.get(small); ok {
+		t.Error("expected small entry to be evicted")
+	}
+	if _, ok := c.get(large); ok {
+		t.Error("expected large entry to be evicted")
+	}
+	if c.bytes > c.maxBytes {
+		t.Errorf("bytes = %d exceeds the limit of %d", c.bytes, c.maxBytes)
+	}
+}
+
+func TestResultSize(t *testing.T) {
+	sd := optimization.NewScoreDistribution()
+	if size := resultSize(sd); size != 4*yahtzee.MaxScore {
+		t.Errorf("resultSize(ScoreDistribution) = %d, expected %d", size, 4*yahtzee.MaxScore)
+	}
+	if size := resultSize(optimization.NewExpectedValue()); size != 4 {
+		t.Errorf("resultSize(ExpectedValue) = %d, expected 4", size)
+	}
+}
+
+func TestResultCacheClearedWhenTableIsSet(t *testing.T) {
+	ys := NewYahtzeeServer(nil, nil, nil)
+	before, _ := ys.getTables()
+	key := outcomeKey{table: TableExpectedValue, generation: before.generation, step: yahtzee.Hold1}
+	before.results.add(key, 0, 0)
+	if _, ok := before.results.get(key); !ok {
+		t.Fatal("expected key to be cached")
+	}
+
+	ys.setTable(TableExpectedValue, nil, &tableStatus{state: TableUnavailable})
+	after, _ := ys.getTables()
+	if after.generation == before.generation {
+		t.Error("expected a new generation after setTable")
+	}
+	if _, ok := after.results.get(key); ok {
+		t.Error("expected the cache to be cleared by setTable")
+	}
+
+	// Outcomes that were computed with the previous tables
+	// are not added once they have been replaced.
+	before.results.add(key, 0, 0)
+	if _, ok := after.results.get(key); ok {
+		t.Error("expected outcomes of a previous generation not to be cached")
+	}
+}
@@ -0,0 +1,61 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/timpalpant/yahtzee"
+	"github.com/timpalpant/yahtzee/optimization"
+)
+
+// DebugGameState decodes a GameState, given either in the notation of
+// yahtzee.ParseGameState (?game=) or by its ID (?id=), e.g. to inspect
+// the GameStates that are written to the logs.
+func (ys *YahtzeeServer) DebugGameState(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, methodNotAllowed(r.Method))
+		return
+	}
+
+	game, err := parseGameStateQuery(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	info := &GameStateInfo{
+		ID:             uint(game),
+		Notation:       game.String(),
+		GameState:      FromYahtzeeGameState(game),
+		TurnsRemaining: game.TurnsRemaining(),
+	}
+
+	t, _ := ys.getTables()
+	if t.expectedValue != nil {
+		if result, ok := t.expectedValue.Lookup(game); ok {
+			value := float32(result.(optimization.ExpectedValue))
+			info.ExpectedValue = &value
+		}
+	}
+
+	writeJSON(w, info)
+}
+
+func parseGameStateQuery(r *http.Request) (yahtzee.GameState, error) {
+	query := r.URL.Query()
+	if s := query.Get("id"); s != "" {
+		id, err := strconv.ParseUint(s, 10, 32)
+		game := yahtzee.GameState(id)
+		if err != nil || game >= yahtzee.MaxGame || !game.IsValid() {
+			return 0, badRequest(ErrCodeInvalidGameState, "id", "invalid game state ID: %v", s)
+		}
+		return game, nil
+	}
+
+	game, err := yahtzee.ParseGameState(query.Get("game"))
+	if err != nil {
+		return 0, badRequest(ErrCodeInvalidGameState, "game", "%v", err)
+	}
+
+	return game, nil
+}
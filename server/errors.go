@@ -0,0 +1,99 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/golang/glog"
+)
+
+// Error codes returned in APIError.Code.
+const (
+	ErrCodeInvalidJSON        = "invalid_json"
+	ErrCodeInvalidBatch       = "invalid_batch"
+	ErrCodeInvalidDice        = "invalid_dice"
+	ErrCodeInvalidBox         = "invalid_box"
+	ErrCodeBoxFilled          = "box_filled"
+	ErrCodeInvalidGameState   = "invalid_game_state"
+	ErrCodeInvalidTurnStep    = "invalid_turn_step"
+	ErrCodeInvalidScoreToBeat = "invalid_score_to_beat"
+	ErrCodeInvalidObjective   = "invalid_objective"
+	ErrCodeInvalidQuantile    = "invalid_quantile"
+	ErrCodeInvalidRange       = "invalid_range"
+	ErrCodeGameOver           = "game_over"
+	ErrCodeInvalidMove        = "invalid_move"
+	ErrCodeNotFound           = "not_found"
+	ErrCodeMethodNotAllowed   = "method_not_allowed"
+	ErrCodeUnavailable        = "table_unavailable"
+	ErrCodeInvalidTable       = "invalid_table"
+	ErrCodeInternal           = "internal"
+)
+
+func newAPIError(status int, code, field, format string, args ...interface{}) *APIError {
+	return &APIError{
+		Code:    code,
+		Field:   field,
+		Message: fmt.Sprintf(format, args...),
+		status:  status,
+	}
+}
+
+func badRequest(code, field, format string, args ...interface{}) *APIError {
+	return newAPIError(http.StatusBadRequest, code, field, format, args...)
+}
+
+func invalidJSON(err error) *APIError {
+	return badRequest(ErrCodeInvalidJSON, "", "invalid JSON: %v", err)
+}
+
+func invalidMove(format string, args ...interface{}) *APIError {
+	return newAPIError(http.StatusConflict, ErrCodeInvalidMove, "", format, args...)
+}
+
+func notFound(format string, args ...interface{}) *APIError {
+	return newAPIError(http.StatusNotFound, ErrCodeNotFound, "", format, args...)
+}
+
+func methodNotAllowed(method string) *APIError {
+	return newAPIError(http.StatusMethodNotAllowed, ErrCodeMethodNotAllowed, "",
+		"method %v not allowed", method)
+}
+
+func unavailable(format string, args ...interface{}) *APIError {
+	return newAPIError(http.StatusServiceUnavailable, ErrCodeUnavailable, "", format, args...)
+}
+
+// asAPIError converts err to an *APIError. Errors that are not already
+// an *APIError are considered internal errors.
+func asAPIError(err error) *APIError {
+	if apiErr, ok := err.(*APIError); ok {
+		return apiErr
+	}
+
+	return newAPIError(http.StatusInternalServerError, ErrCodeInternal, "", "%v", err)
+}
+
+// reportError converts err to an *APIError to be returned to the
+// client, and records it in the error metrics.
+func reportError(err error) *APIError {
+	apiErr := asAPIError(err)
+	errorCount.Inc(apiErr.Code)
+	return apiErr
+}
+
+// writeError responds with the status code and JSON ErrorResponse for err.
+func writeError(w http.ResponseWriter, err error) {
+	apiErr := reportError(err)
+	if apiErr.status >= http.StatusInternalServerError {
+		glog.Error(apiErr)
+	} else {
+		glog.Warning(apiErr)
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(apiErr.status)
+	if err := json.NewEncoder(w).Encode(ErrorResponse{*apiErr}); err != nil {
+		glog.Warning(err)
+	}
+}
@@ -0,0 +1,275 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/golang/glog"
+
+	"github.com/timpalpant/yahtzee"
+	"github.com/timpalpant/yahtzee/optimization"
+)
+
+// Explain ranks every choice available in a position, and explains
+// what each choice is trying to achieve.
+func (ys *YahtzeeServer) Explain(w http.ResponseWriter, r *http.Request) {
+	req := &ExplainRequest{}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		writeError(w, invalidJSON(err))
+		return
+	}
+
+	resp, err := ys.ComputeExplanation(req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, resp)
+}
+
+// ComputeExplanation ranks and explains every choice in the position
+// in req. Errors are *APIError.
+func (ys *YahtzeeServer) ComputeExplanation(req *ExplainRequest) (*ExplainResponse, error) {
+	if err := req.validate(); err != nil {
+		return nil, err
+	}
+
+	required := []string{TableExpectedValue}
+	if req.ScoreToBeat > 0 {
+		required = append(required, TableScoreDistribution)
+	}
+	t, err := ys.getTables(required...)
+	if err != nil {
+		return nil, err
+	}
+
+	game := req.GameState.ToYahtzeeGameState()
+	roll := asRoll(req.TurnState.Dice)
+	step := req.TurnState.Step
+	glog.Infof("Explaining choices for game: %v, roll: %v", game, roll)
+
+	resp := &ExplainResponse{}
+	if step == yahtzee.Hold1 || step == yahtzee.Hold2 {
+		expectedScores := t.holdOutcomes(TableExpectedValue, game, step, roll)
+		var scoreDistributions map[yahtzee.Roll]optimization.GameResult
+		if req.ScoreToBeat > 0 {
+			scoreDistributions = t.holdOutcomes(TableScoreDistribution, game, step, roll)
+		}
+
+		for held, es := range expectedScores {
+			choice := ExplainedHold{
+				HeldDice:           held.Dice(),
+				ExpectedFinalScore: float32(es.(optimization.ExpectedValue)),
+				ProbabilityToBeat:  1,
+			}
+			if scoreDistributions != nil {
+				choice.ProbabilityToBeat = gameResultValue(scoreDistributions[held], req.ScoreToBeat)
+			}
+			choice.Category, choice.Description = classifyHold(game, held)
+			resp.HoldChoices = append(resp.HoldChoices, choice)
+		}
+	}
+
+	expectedScores := t.fillOutcomes(TableExpectedValue, game, roll)
+	var scoreDistributions map[yahtzee.Box]optimization.GameResult
+	if req.ScoreToBeat > 0 {
+		scoreDistributions = t.fillOutcomes(TableScoreDistribution, game, roll)
+	}
+
+	for box, es := range expectedScores {
+		choice := ExplainedFill{
+			BoxFilled:          int(box),
+			ExpectedFinalScore: float32(es.(optimization.ExpectedValue)),
+			ProbabilityToBeat:  1,
+		}
+		if scoreDistributions != nil {
+			choice.ProbabilityToBeat = gameResultValue(scoreDistributions[box], req.ScoreToBeat)
+		}
+		choice.Category, choice.Description = classifyFill(game, roll, box)
+		resp.FillChoices = append(resp.FillChoices, choice)
+	}
+
+	rankChoices(resp, req.ScoreToBeat > 0)
+	return resp, nil
+}
+
+// rankedChoice is a hold or fill choice being ranked.
+type rankedChoice struct {
+	expectedValue     float32
+	probabilityToBeat float32
+	explanation       *Explanation
+}
+
+// rankChoices sets the rank and gaps of every choice in resp, and
+// orders the choices from best to worst. Choices are ranked by
+// ProbabilityToBeat if byProbability, and by ExpectedFinalScore otherwise.
+// Choices with equal values share the same rank.
+func rankChoices(resp *ExplainResponse, byProbability bool) {
+	choices := make([]rankedChoice, 0, len(resp.HoldChoices)+len(resp.FillChoices))
+	for i := range resp.HoldChoices {
+		c := &resp.HoldChoices[i]
+		choices = append(choices, rankedChoice{c.ExpectedFinalScore, c.ProbabilityToBeat, &c.Explanation})
+	}
+	for i := range resp.FillChoices {
+		c := &resp.FillChoices[i]
+		choices = append(choices, rankedChoice{c.ExpectedFinalScore, c.ProbabilityToBeat, &c.Explanation})
+	}
+
+	better := func(a, b rankedChoice) bool {
+		if byProbability && a.probabilityToBeat != b.probabilityToBeat {
+			return a.probabilityToBeat > b.probabilityToBeat
+		}
+		return a.expectedValue > b.expectedValue
+	}
+	sort.SliceStable(choices, func(i, j int) bool {
+		return better(choices[i], choices[j])
+	})
+
+	var bestValue, bestProbability float32
+	for _, c := range choices {
+		if c.expectedValue > bestValue {
+			bestValue = c.expectedValue
+		}
+		if c.probabilityToBeat > bestProbability {
+			bestProbability = c.probabilityToBeat
+		}
+	}
+
+	for i, c := range choices {
+		c.explanation.Rank = i + 1
+		if i > 0 && !better(choices[i-1], c) {
+			c.explanation.Rank = choices[i-1].explanation.Rank
+		}
+		c.explanation.ExpectedValueGap = bestValue - c.expectedValue
+		if byProbability {
+			c.explanation.ProbabilityToBeatGap = bestProbability - c.probabilityToBeat
+		}
+	}
+
+	sort.SliceStable(resp.HoldChoices, func(i, j int) bool {
+		return resp.HoldChoices[i].Rank < resp.HoldChoices[j].Rank
+	})
+	sort.SliceStable(resp.FillChoices, func(i, j int) bool {
+		return resp.FillChoices[i].Rank < resp.FillChoices[j].Rank
+	})
+}
+
+// countNames are the names of the number of dice held.
+var countNames = [...]string{"no", "one", "two", "three", "four", "five"}
+
+// classifyHold describes what holding the given dice is trying to
+// achieve, given the boxes that are still open in the game.
+func classifyHold(game yahtzee.GameState, held yahtzee.Roll) (ChoiceCategory, string) {
+	n := held.NumDice()
+	if n == yahtzee.NDice {
+		return CategoryKeepAll, "keeping all of the dice"
+	} else if n == 0 {
+		return CategoryRerollAll, "rerolling all of the dice"
+	}
+
+	open := func(box yahtzee.Box) bool { return !game.BoxFilled(box) }
+	counts := held.Counts()
+	side, count, distinct := 0, 0, 0
+	lo, hi := yahtzee.NSides, 1
+	for i, c := range counts {
+		if c == 0 {
+			continue
+		}
+
+		distinct++
+		if c >= count {
+			side, count = i+1, c
+		}
+		if i+1 < lo {
+			lo = i + 1
+		}
+		hi = i + 1
+	}
+
+	switch {
+	case distinct == 1:
+		return classifyOfAKind(game, side, count)
+	case distinct == 2 && n-count >= 2 && open(yahtzee.FullHouse):
+		return CategoryFullHouse, fmt.Sprintf("going for a Full House with %vs and %vs", lo, hi)
+	case distinct == n:
+		// All of the held dice are different, which only makes
+		// sense when going for a straight or keeping high dice.
+		if open(yahtzee.LargeStraight) && hi-lo < 5 && (n >= 4 || hi-lo == 4 || !open(yahtzee.SmallStraight)) {
+			return CategoryLargeStraight, "going for a Large Straight"
+		} else if open(yahtzee.SmallStraight) && hi-lo < 4 {
+			return CategorySmallStraight, "going for a Small Straight"
+		} else if open(yahtzee.LargeStraight) && hi-lo < 5 {
+			return CategoryLargeStraight, "going for a Large Straight"
+		}
+	}
+
+	if open(yahtzee.Chance) && lo >= 4 {
+		return CategoryChance, "keeping high dice for Chance"
+	}
+
+	return CategoryOther, "keeping " + joinDice(held.Dice())
+}
+
+// classifyOfAKind describes holding count dice of the same side.
+func classifyOfAKind(game yahtzee.GameState, side, count int) (ChoiceCategory, string) {
+	open := func(box yahtzee.Box) bool { return !game.BoxFilled(box) }
+	upper := yahtzee.Box(side - 1)
+	switch {
+	case count >= 3 && (open(yahtzee.Yahtzee) || game.BonusEligible()):
+		return CategoryYahtzee, fmt.Sprintf("going for a Yahtzee with %v %vs", countNames[count], side)
+	case open(upper) && game.UpperHalfScore() < yahtzee.UpperHalfBonusThreshold:
+		return CategoryUpperBonus, fmt.Sprintf("keeping %vs for the upper bonus", side)
+	case count >= 2 && open(yahtzee.FourOfAKind):
+		return CategoryOfAKind, fmt.Sprintf("going for Four of a Kind with %vs", side)
+	case count >= 2 && open(yahtzee.ThreeOfAKind):
+		return CategoryOfAKind, fmt.Sprintf("going for Three of a Kind with %vs", side)
+	case open(upper):
+		return CategoryUpper, fmt.Sprintf("keeping %vs for %v", side, upper)
+	case count >= 2 && open(yahtzee.Yahtzee):
+		return CategoryYahtzee, fmt.Sprintf("going for a Yahtzee with %v %vs", countNames[count], side)
+	case open(yahtzee.Chance) && side >= 4:
+		return CategoryChance, "keeping high dice for Chance"
+	}
+
+	if count == 1 {
+		return CategoryOther, fmt.Sprintf("keeping a %v", side)
+	}
+
+	return CategoryOther, fmt.Sprintf("keeping %v %vs", countNames[count], side)
+}
+
+// classifyFill describes the result of filling box with roll.
+func classifyFill(game yahtzee.GameState, roll yahtzee.Roll, box yahtzee.Box) (ChoiceCategory, string) {
+	newGame, value := game.FillBox(box, roll)
+	switch {
+	case value == 0:
+		return CategoryScratch, fmt.Sprintf("scratching %v for 0 points", box)
+	case game.BonusEligible() && yahtzee.IsYahtzee(roll):
+		return CategoryYahtzeeBonus, fmt.Sprintf("scoring %d in %v with a Yahtzee bonus", value, box)
+	case box.IsUpperHalf() && game.UpperHalfScore() < yahtzee.UpperHalfBonusThreshold &&
+		newGame.UpperHalfScore() >= yahtzee.UpperHalfBonusThreshold:
+		return CategoryUpperBonus, fmt.Sprintf("scoring %d in %v and earning the upper bonus",
+			box.Score(roll), box)
+	}
+
+	return CategoryScore, fmt.Sprintf("scoring %d in %v", value, box)
+}
+
+// joinDice formats dice as a list, e.g. "1, 2 and 5".
+func joinDice(dice []int) string {
+	strs := make([]string, len(dice))
+	for i, die := range dice {
+		strs[i] = strconv.Itoa(die)
+	}
+
+	if len(strs) == 1 {
+		return strs[0]
+	}
+
+	return strings.Join(strs[:len(strs)-1], ", ") + " and " + strs[len(strs)-1]
+}
@@ -0,0 +1,125 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/timpalpant/yahtzee"
+)
+
+func TestClassifyHold(t *testing.T) {
+	game := yahtzee.NewGame()
+	lateGame := game
+	for _, box := range []yahtzee.Box{yahtzee.Yahtzee, yahtzee.FourOfAKind, yahtzee.ThreeOfAKind} {
+		lateGame = lateGame.SetBoxFilled(box)
+	}
+
+	testCases := []struct {
+		game     yahtzee.GameState
+		held     []int
+		expected ChoiceCategory
+	}{
+		{game, []int{}, CategoryRerollAll},
+		{game, []int{1, 2, 3, 4, 5}, CategoryKeepAll},
+		{game, []int{6, 6, 6}, CategoryYahtzee},
+		{game, []int{6, 6}, CategoryUpperBonus},
+		{lateGame, []int{6, 6, 6}, CategoryUpperBonus},
+		{lateGame.SetBoxFilled(yahtzee.Sixes), []int{6, 6}, CategoryChance},
+		{game, []int{2, 2, 5, 5}, CategoryFullHouse},
+		{game, []int{2, 3, 4}, CategorySmallStraight},
+		{game, []int{2, 3, 4, 5}, CategoryLargeStraight},
+		{game.SetBoxFilled(yahtzee.SmallStraight), []int{2, 3}, CategoryLargeStraight},
+		{game, []int{5, 6}, CategorySmallStraight},
+		{game.SetBoxFilled(yahtzee.SmallStraight).SetBoxFilled(yahtzee.LargeStraight), []int{5, 6}, CategoryChance},
+		{game, []int{1, 6}, CategoryOther},
+	}
+
+	for _, tc := range testCases {
+		category, description := classifyHold(tc.game, yahtzee.NewRollFromDice(tc.held))
+		if category != tc.expected {
+			t.Errorf("classifyHold(%v) = %v (%v), expected %v",
+				tc.held, category, description, tc.expected)
+		}
+	}
+}
+
+func TestClassifyFill(t *testing.T) {
+	game := yahtzee.NewGame().AddUpperHalfScore(50)
+	testCases := []struct {
+		game     yahtzee.GameState
+		dice     []int
+		box      yahtzee.Box
+		expected ChoiceCategory
+	}{
+		{game, []int{6, 6, 6, 1, 2}, yahtzee.Sixes, CategoryUpperBonus},
+		{game, []int{6, 1, 1, 1, 2}, yahtzee.Sixes, CategoryScore},
+		{game, []int{6, 1, 1, 1, 2}, yahtzee.Yahtzee, CategoryScratch},
+		{game.SetBoxFilled(yahtzee.Yahtzee).SetBonusEligible(), []int{4, 4, 4, 4, 4}, yahtzee.Chance, CategoryYahtzeeBonus},
+	}
+
+	for _, tc := range testCases {
+		category, description := classifyFill(tc.game, yahtzee.NewRollFromDice(tc.dice), tc.box)
+		if category != tc.expected {
+			t.Errorf("classifyFill(%v, %v) = %v (%v), expected %v",
+				tc.dice, tc.box, category, description, tc.expected)
+		}
+	}
+}
+
+func TestRankChoices(t *testing.T) {
+	testCases := []struct {
+		byProbability bool
+		holds         []ExplainedHold
+		fills         []ExplainedFill
+		// The expected ranks, after sorting.
+		holdRanks []int
+		fillRanks []int
+	}{
+		// A hold and a fill with equal values share rank 1, and the
+		// next choice is ranked 3.
+		{false,
+			[]ExplainedHold{{ExpectedFinalScore: 180}, {ExpectedFinalScore: 200}},
+			[]ExplainedFill{{ExpectedFinalScore: 150}, {ExpectedFinalScore: 200}},
+			[]int{1, 3}, []int{1, 4}},
+		// Ties in ProbabilityToBeat are broken by ExpectedFinalScore.
+		{true,
+			[]ExplainedHold{{ExpectedFinalScore: 200, ProbabilityToBeat: 0.5}, {ExpectedFinalScore: 190, ProbabilityToBeat: 0.6}},
+			[]ExplainedFill{{ExpectedFinalScore: 210, ProbabilityToBeat: 0.1}, {ExpectedFinalScore: 190, ProbabilityToBeat: 0.6}},
+			[]int{1, 3}, []int{1, 4}},
+		{true,
+			[]ExplainedHold{{ExpectedFinalScore: 180, ProbabilityToBeat: 0.6}, {ExpectedFinalScore: 190, ProbabilityToBeat: 0.6}},
+			nil,
+			[]int{1, 2}, nil},
+	}
+
+	for i, tc := range testCases {
+		resp := &ExplainResponse{HoldChoices: tc.holds, FillChoices: tc.fills}
+		rankChoices(resp, tc.byProbability)
+		for j, c := range resp.HoldChoices {
+			if c.Rank != tc.holdRanks[j] {
+				t.Errorf("case %d: hold %d (%v) has rank %v, expected %v",
+					i, j, c.ExpectedFinalScore, c.Rank, tc.holdRanks[j])
+			}
+		}
+		for j, c := range resp.FillChoices {
+			if c.Rank != tc.fillRanks[j] {
+				t.Errorf("case %d: fill %d (%v) has rank %v, expected %v",
+					i, j, c.ExpectedFinalScore, c.Rank, tc.fillRanks[j])
+			}
+		}
+	}
+}
+
+func TestRankChoicesGaps(t *testing.T) {
+	resp := &ExplainResponse{
+		HoldChoices: []ExplainedHold{{ExpectedFinalScore: 190, ProbabilityToBeat: 0.6}},
+		FillChoices: []ExplainedFill{{ExpectedFinalScore: 210, ProbabilityToBeat: 0.1}},
+	}
+	rankChoices(resp, true)
+	hold, fill := resp.HoldChoices[0], resp.FillChoices[0]
+	if hold.ExpectedValueGap != 20 || hold.ProbabilityToBeatGap != 0 {
+		t.Errorf("hold gaps = %v, %v, expected 20, 0", hold.ExpectedValueGap, hold.ProbabilityToBeatGap)
+	}
+	if fill.ExpectedValueGap != 0 || fill.ProbabilityToBeatGap != 0.5 {
+		t.Errorf("fill gaps = %v, %v, expected 0, 0.5", fill.ExpectedValueGap, fill.ProbabilityToBeatGap)
+	}
+}
@@ -0,0 +1,69 @@
+//go:build ignore
+// +build ignore
+
+// gen_assets generates assets_gen.go, which embeds the web UI
+// templates and static files in the server binary.
+// Run it with go generate after changing any of the assets.
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+)
+
+var assetDirs = []string{"templates", "static"}
+
+func main() {
+	assets := make(map[string][]byte)
+	for _, dir := range assetDirs {
+		err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+			if err != nil || info.IsDir() || info.Name()[0] == '.' {
+				return err
+			}
+
+			data, err := ioutil.ReadFile(path)
+			if err != nil {
+				return err
+			}
+
+			assets[filepath.ToSlash(path)] = data
+			return nil
+		})
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	names := make([]string, 0, len(assets))
+	for name := range assets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by gen_assets.go; DO NOT EDIT.\n\n")
+	buf.WriteString("package server\n\n")
+	buf.WriteString("// embeddedAssets are the contents of the web UI templates and\n")
+	buf.WriteString("// static files, by path relative to the server package.\n")
+	buf.WriteString("var embeddedAssets = map[string]string{\n")
+	for _, name := range names {
+		fmt.Fprintf(&buf, "\t%q: %s,\n", name, strconv.Quote(string(assets[name])))
+	}
+	buf.WriteString("}\n")
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := ioutil.WriteFile("assets_gen.go", src, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,140 @@
+package server
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"sort"
+
+	"github.com/golang/glog"
+
+	"github.com/timpalpant/yahtzee"
+	"github.com/timpalpant/yahtzee/server/websocket"
+)
+
+// LiveAdvice upgrades the connection to a WebSocket, over which the
+// client streams a LiveAdviceRequest each time the dice change, and the
+// server pushes back a LiveAdviceResponse with updated advice.
+//
+// If new dice arrive while advice is still being computed, only the
+// latest request is answered.
+func (ys *YahtzeeServer) LiveAdvice(w http.ResponseWriter, r *http.Request) {
+	ys.mu.RLock()
+	allowedOrigins := ys.allowedOrigins
+	ys.mu.RUnlock()
+
+	conn, err := websocket.Upgrade(w, r, allowedOrigins)
+	if err != nil {
+		glog.Warning(err)
+		return
+	}
+	defer conn.Close()
+
+	pending := make(chan *LiveAdviceRequest, 1)
+	go func() {
+		defer close(pending)
+		for {
+			msg, err := conn.ReadMessage()
+			if err != nil {
+				if err != io.EOF {
+					glog.Warning(err)
+				}
+				return
+			}
+
+			req := &LiveAdviceRequest{}
+			if err := json.Unmarshal(msg, req); err != nil {
+				glog.Warning(err)
+				conn.WriteJSON(&LiveAdviceResponse{Error: reportError(invalidJSON(err))})
+				continue
+			} else if diceIncomplete(req.TurnState) {
+				continue // Dice are still being entered.
+			}
+
+			// Replace any request that has not been answered yet.
+			select {
+			case <-pending:
+			default:
+			}
+			pending <- req
+		}
+	}()
+
+	for req := range pending {
+		resp := ys.getLiveAdvice(req)
+		if err := conn.WriteJSON(resp); err != nil {
+			glog.Warning(err)
+			return
+		}
+	}
+}
+
+// diceIncomplete returns true if some, but not all, of the dice of a
+// roll have been entered. Other requests (including those without
+// any dice) are answered, with an Error if they are invalid.
+func diceIncomplete(ts TurnState) bool {
+	return ts.Step != yahtzee.Begin && len(ts.Dice) > 0 && len(ts.Dice) < yahtzee.NDice
+}
+
+func (ys *YahtzeeServer) getLiveAdvice(req *LiveAdviceRequest) *LiveAdviceResponse {
+	resp := &LiveAdviceResponse{TurnState: req.TurnState}
+	if err := req.validate(); err != nil {
+		resp.Error = reportError(err)
+		return resp
+	}
+
+	outcomes, err := ys.ComputeOutcomeDistribution(&OutcomeDistributionRequest{
+		GameState: req.GameState,
+		TurnState: req.TurnState,
+	})
+	if err != nil {
+		resp.Error = reportError(err)
+		return resp
+	}
+
+	for _, choice := range outcomes.HoldChoices {
+		resp.HoldChoices = append(resp.HoldChoices, HoldAdvice{
+			HeldDice:           choice.HeldDice,
+			ExpectedFinalScore: choice.ExpectedFinalScore,
+			ProbabilityToBeat:  probabilityToBeat(choice.FinalScoreDistribution, req.ScoreToBeat),
+		})
+	}
+
+	for _, choice := range outcomes.FillChoices {
+		resp.FillChoices = append(resp.FillChoices, FillAdvice{
+			BoxFilled:          choice.BoxFilled,
+			ExpectedFinalScore: choice.ExpectedFinalScore,
+			ProbabilityToBeat:  probabilityToBeat(choice.FinalScoreDistribution, req.ScoreToBeat),
+		})
+	}
+
+	byProbability := req.ScoreToBeat > 0
+	sort.SliceStable(resp.HoldChoices, func(i, j int) bool {
+		a, b := resp.HoldChoices[i], resp.HoldChoices[j]
+		if byProbability && a.ProbabilityToBeat != b.ProbabilityToBeat {
+			return a.ProbabilityToBeat > b.ProbabilityToBeat
+		}
+		return a.ExpectedFinalScore > b.ExpectedFinalScore
+	})
+	sort.SliceStable(resp.FillChoices, func(i, j int) bool {
+		a, b := resp.FillChoices[i], resp.FillChoices[j]
+		if byProbability && a.ProbabilityToBeat != b.ProbabilityToBeat {
+			return a.ProbabilityToBeat > b.ProbabilityToBeat
+		}
+		return a.ExpectedFinalScore > b.ExpectedFinalScore
+	})
+
+	return resp
+}
+
+// probabilityToBeat returns the probability of achieving at least
+// the given score from a final score distribution.
+func probabilityToBeat(distribution []float32, scoreToBeat int) float32 {
+	if scoreToBeat <= 0 {
+		return 1
+	} else if scoreToBeat >= len(distribution) {
+		return 0
+	}
+
+	return distribution[scoreToBeat]
+}
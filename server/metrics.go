@@ -0,0 +1,117 @@
+package server
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/golang/glog"
+
+	"github.com/timpalpant/yahtzee/optimization"
+	"github.com/timpalpant/yahtzee/server/metrics"
+)
+
+var (
+	registry = metrics.NewRegistry()
+
+	requestCount = registry.NewCounter("yahtzee_http_requests_total",
+		"Number of HTTP requests by endpoint and status code.", "endpoint", "code")
+	requestLatency = registry.NewHistogram("yahtzee_http_request_duration_seconds",
+		"Latency of HTTP requests by endpoint.", metrics.DefaultBuckets, "endpoint")
+	errorCount = registry.NewCounter("yahtzee_errors_total",
+		"Number of errors returned to clients by error code.", "code")
+
+	tableLoaded = registry.NewGauge("yahtzee_table_loaded",
+		"Whether each strategy table is loaded (1) or not (0).", "table")
+	tableLoadSeconds = registry.NewGauge("yahtzee_table_load_seconds",
+		"Time taken to load each strategy table.", "table")
+	tableEntries = registry.NewGauge("yahtzee_table_entries",
+		"Number of game states in each strategy table.", "table")
+
+	resultCacheRequests = registry.NewCounter("yahtzee_result_cache_requests_total",
+		"Number of result cache lookups by result (hit or miss).", "result")
+	resultCacheEvictions = registry.NewCounter("yahtzee_result_cache_evictions_total",
+		"Number of entries evicted from the result cache.")
+	resultCacheEntries = registry.NewGauge("yahtzee_result_cache_entries",
+		"Number of entries in the result cache.")
+	resultCacheBytes = registry.NewGauge("yahtzee_result_cache_bytes",
+		"Estimated memory used by the entries in the result cache.")
+)
+
+func init() {
+	registry.NewCounterFunc("yahtzee_turn_optimizer_caches_allocated_total",
+		"Number of TurnOptimizer caches allocated by the cache pool.",
+		func() float64 {
+			allocated, _ := optimization.CachePoolStats()
+			return float64(allocated)
+		})
+	registry.NewGaugeFunc("yahtzee_turn_optimizer_caches_in_use",
+		"Number of TurnOptimizer caches currently in use.",
+		func() float64 {
+			_, inUse := optimization.CachePoolStats()
+			return float64(inUse)
+		})
+}
+
+// Metrics exports server metrics in the Prometheus text format.
+func (ys *YahtzeeServer) Metrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	if err := registry.Write(w); err != nil {
+		glog.Warning(err)
+	}
+}
+
+// Instrument wraps the handler for the given endpoint to record
+// the number of requests and their latency.
+func Instrument(endpoint string, h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		h.ServeHTTP(rec, r)
+		requestCount.Inc(endpoint, strconv.Itoa(rec.status))
+		requestLatency.Observe(time.Since(start).Seconds(), endpoint)
+	})
+}
+
+// statusRecorder records the status code written to a ResponseWriter.
+// It supports flushing (for streaming responses) and hijacking
+// (for WebSockets) if the underlying ResponseWriter does.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (rec *statusRecorder) WriteHeader(status int) {
+	rec.status = status
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *statusRecorder) Flush() {
+	if f, ok := rec.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (rec *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hj, ok := rec.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, http.ErrNotSupported
+	}
+
+	rec.status = http.StatusSwitchingProtocols
+	return hj.Hijack()
+}
+
+// recordTable updates the metrics for a table when it is (re)loaded.
+func recordTable(name string, strat *optimization.Strategy, status *tableStatus) {
+	loaded, entries := 0.0, 0.0
+	if strat != nil {
+		loaded, entries = 1, float64(strat.Count())
+	}
+
+	tableLoaded.Set(loaded, name)
+	tableEntries.Set(entries, name)
+	tableLoadSeconds.Set(status.loadTime.Seconds(), name)
+}
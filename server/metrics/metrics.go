@@ -0,0 +1,313 @@
+// Package metrics implements counters, gauges and histograms that can
+// be exported in the Prometheus text exposition format, so that they
+// can be scraped without any additional services or dependencies.
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultBuckets are histogram buckets (in seconds) suitable for
+// measuring request latency.
+var DefaultBuckets = []float64{
+	0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60,
+}
+
+// Registry is a set of metrics that are exported together.
+type Registry struct {
+	mu      sync.Mutex
+	metrics []metric
+}
+
+type metric interface {
+	writeTo(w *bufio.Writer)
+}
+
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+func (r *Registry) register(m metric) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.metrics = append(r.metrics, m)
+}
+
+// Write writes all metrics in the registry to w
+// in the Prometheus text format.
+func (r *Registry) Write(w io.Writer) error {
+	r.mu.Lock()
+	metrics := append([]metric(nil), r.metrics...)
+	r.mu.Unlock()
+
+	bw := bufio.NewWriter(w)
+	for _, m := range metrics {
+		m.writeTo(bw)
+	}
+
+	return bw.Flush()
+}
+
+// desc describes a metric family.
+type desc struct {
+	name   string
+	help   string
+	kind   string
+	labels []string
+}
+
+func (d *desc) writeHeader(w *bufio.Writer) {
+	fmt.Fprintf(w, "# HELP %s %s\n", d.name, escapeHelp(d.help))
+	fmt.Fprintf(w, "# TYPE %s %s\n", d.name, d.kind)
+}
+
+// series holds the values of a metric for each combination of labels.
+type series struct {
+	mu     sync.Mutex
+	values map[string]*labeled
+}
+
+type labeled struct {
+	labelValues []string
+	value       interface{}
+}
+
+// get returns the value for the given labels, initializing
+// it with newValue if it does not exist yet.
+func (s *series) get(d *desc, labelValues []string, newValue func() interface{}) interface{} {
+	if len(labelValues) != len(d.labels) {
+		panic(fmt.Errorf("metrics: %v has labels %v, got values %v",
+			d.name, d.labels, labelValues))
+	}
+
+	key := strings.Join(labelValues, "\xff")
+	if l, ok := s.values[key]; ok {
+		return l.value
+	}
+
+	if s.values == nil {
+		s.values = make(map[string]*labeled)
+	}
+
+	l := &labeled{append([]string(nil), labelValues...), newValue()}
+	s.values[key] = l
+	return l.value
+}
+
+// sorted returns the values ordered by their labels.
+func (s *series) sorted() []*labeled {
+	result := make([]*labeled, 0, len(s.values))
+	for _, l := range s.values {
+		result = append(result, l)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return strings.Join(result[i].labelValues, "\xff") <
+			strings.Join(result[j].labelValues, "\xff")
+	})
+
+	return result
+}
+
+// Counter is a monotonically increasing value, with optional labels.
+type Counter struct {
+	desc
+	series
+}
+
+// NewCounter registers a new counter with the given label names.
+func (r *Registry) NewCounter(name, help string, labels ...string) *Counter {
+	c := &Counter{desc: desc{name, help, "counter", labels}}
+	r.register(c)
+	return c
+}
+
+// Inc increments the counter with the given label values by 1.
+func (c *Counter) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add increments the counter with the given label values by v.
+func (c *Counter) Add(v float64, labelValues ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	value := c.get(&c.desc, labelValues, func() interface{} { return new(float64) })
+	*value.(*float64) += v
+}
+
+func (c *Counter) writeTo(w *bufio.Writer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.writeHeader(w)
+	for _, l := range c.sorted() {
+		writeSample(w, c.name, c.labels, l.labelValues, "", "", *l.value.(*float64))
+	}
+}
+
+// Gauge is a value that may go up or down, with optional labels.
+type Gauge struct {
+	desc
+	series
+}
+
+// NewGauge registers a new gauge with the given label names.
+func (r *Registry) NewGauge(name, help string, labels ...string) *Gauge {
+	g := &Gauge{desc: desc{name, help, "gauge", labels}}
+	r.register(g)
+	return g
+}
+
+// Set sets the gauge with the given label values to v.
+func (g *Gauge) Set(v float64, labelValues ...string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	value := g.get(&g.desc, labelValues, func() interface{} { return new(float64) })
+	*value.(*float64) = v
+}
+
+func (g *Gauge) writeTo(w *bufio.Writer) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.writeHeader(w)
+	for _, l := range g.sorted() {
+		writeSample(w, g.name, g.labels, l.labelValues, "", "", *l.value.(*float64))
+	}
+}
+
+// valueFunc is a metric without labels whose value
+// is computed when it is exported.
+type valueFunc struct {
+	desc
+	fn func() float64
+}
+
+// NewCounterFunc registers a counter whose value is returned by fn.
+// The values returned by fn must never decrease.
+func (r *Registry) NewCounterFunc(name, help string, fn func() float64) {
+	r.register(&valueFunc{desc{name, help, "counter", nil}, fn})
+}
+
+// NewGaugeFunc registers a gauge whose value is returned by fn.
+func (r *Registry) NewGaugeFunc(name, help string, fn func() float64) {
+	r.register(&valueFunc{desc{name, help, "gauge", nil}, fn})
+}
+
+func (f *valueFunc) writeTo(w *bufio.Writer) {
+	f.writeHeader(w)
+	writeSample(w, f.name, nil, nil, "", "", f.fn())
+}
+
+// Histogram counts observations in buckets, with optional labels.
+type Histogram struct {
+	desc
+	series
+	buckets []float64
+}
+
+type histogramValue struct {
+	counts []uint64 // Not cumulative.
+	count  uint64
+	sum    float64
+}
+
+// NewHistogram registers a new histogram with the given upper bounds
+// of its buckets (in increasing order) and label names.
+func (r *Registry) NewHistogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	h := &Histogram{desc: desc{name, help, "histogram", labels}, buckets: buckets}
+	r.register(h)
+	return h
+}
+
+// Observe adds an observation of v to the histogram
+// with the given label values.
+func (h *Histogram) Observe(v float64, labelValues ...string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	value := h.get(&h.desc, labelValues, func() interface{} {
+		return &histogramValue{counts: make([]uint64, len(h.buckets))}
+	}).(*histogramValue)
+
+	i := sort.SearchFloat64s(h.buckets, v)
+	if i < len(h.buckets) {
+		value.counts[i]++
+	}
+	value.count++
+	value.sum += v
+}
+
+func (h *Histogram) writeTo(w *bufio.Writer) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.writeHeader(w)
+	for _, l := range h.sorted() {
+		value := l.value.(*histogramValue)
+		var cumulative uint64
+		for i, upper := range h.buckets {
+			cumulative += value.counts[i]
+			writeSample(w, h.name+"_bucket", h.labels, l.labelValues,
+				"le", formatFloat(upper), float64(cumulative))
+		}
+		writeSample(w, h.name+"_bucket", h.labels, l.labelValues,
+			"le", "+Inf", float64(value.count))
+		writeSample(w, h.name+"_sum", h.labels, l.labelValues, "", "", value.sum)
+		writeSample(w, h.name+"_count", h.labels, l.labelValues, "", "", float64(value.count))
+	}
+}
+
+// writeSample writes a single line of the exposition format. If extraLabel
+// is not empty, it is added after the other labels (e.g. "le" for buckets).
+func writeSample(w *bufio.Writer, name string, labels, labelValues []string,
+	extraLabel, extraValue string, v float64) {
+	w.WriteString(name)
+	if len(labels) > 0 || extraLabel != "" {
+		w.WriteByte('{')
+		for i, label := range labels {
+			if i > 0 {
+				w.WriteByte(',')
+			}
+			fmt.Fprintf(w, "%s=\"%s\"", label, escapeLabel(labelValues[i]))
+		}
+		if extraLabel != "" {
+			if len(labels) > 0 {
+				w.WriteByte(',')
+			}
+			fmt.Fprintf(w, "%s=\"%s\"", extraLabel, extraValue)
+		}
+		w.WriteByte('}')
+	}
+	w.WriteByte(' ')
+	w.WriteString(formatFloat(v))
+	w.WriteByte('\n')
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabel(s string) string {
+	return labelEscaper.Replace(s)
+}
+
+var helpEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+
+func escapeHelp(s string) string {
+	return helpEscaper.Replace(s)
+}
@@ -0,0 +1,48 @@
+package metrics
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestRegistryWrite(t *testing.T) {
+	r := NewRegistry()
+	c := r.NewCounter("requests_total", "Number of requests.", "endpoint", "code")
+	c.Inc("score", "200")
+	c.Inc("score", "200")
+	c.Add(3, "move", "400")
+	g := r.NewGauge("loaded", "Whether the table is loaded.", "table")
+	g.Set(1, `a"b`)
+	h := r.NewHistogram("latency_seconds", "Request latency.", []float64{0.1, 1})
+	h.Observe(0.05)
+	h.Observe(0.5)
+	h.Observe(2)
+	r.NewGaugeFunc("in_use", "Caches in use.", func() float64 { return 4 })
+
+	expected := `# HELP requests_total Number of requests.
+# TYPE requests_total counter
+requests_total{endpoint="move",code="400"} 3
+requests_total{endpoint="score",code="200"} 2
+# HELP loaded Whether the table is loaded.
+# TYPE loaded gauge
+loaded{table="a\"b"} 1
+# HELP latency_seconds Request latency.
+# TYPE latency_seconds histogram
+latency_seconds_bucket{le="0.1"} 1
+latency_seconds_bucket{le="1"} 2
+latency_seconds_bucket{le="+Inf"} 3
+latency_seconds_sum 2.55
+latency_seconds_count 3
+# HELP in_use Caches in use.
+# TYPE in_use gauge
+in_use 4
+`
+
+	var buf bytes.Buffer
+	if err := r.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != expected {
+		t.Errorf("Write() =\n%v\nexpected:\n%v", buf.String(), expected)
+	}
+}
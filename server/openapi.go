@@ -0,0 +1,439 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+
+	"github.com/timpalpant/yahtzee"
+)
+
+// operation describes a single endpoint of the API for the OpenAPI
+// document. The request and response schemas are generated from the
+// types of Request and Response, so that the document is always in
+// lockstep with the types in api.go.
+type operation struct {
+	Method  string
+	Path    string
+	Summary string
+	// Request is a value of the request body type, or nil if the
+	// operation does not have a request body.
+	Request interface{}
+	// Response is a value of the response body type, or nil if the
+	// operation does not have a response body.
+	Response interface{}
+	// Status is the status code of a successful response.
+	Status int
+	// ContentType of a successful response, if not application/json.
+	ContentType string
+	// Errors are the bodies of error responses that are not an
+	// ErrorResponse, by status code.
+	Errors map[int]interface{}
+	// Query are the names of the optional (string) query parameters.
+	Query []string
+}
+
+// operations are all of the endpoints of the API.
+var operations = []operation{
+	{Method: http.MethodPost, Path: "/rest/v1/score",
+		Summary: "Get the score of playing dice in a box.",
+		Request: GetScoreRequest{}, Response: GetScoreResponse{}},
+	{Method: http.MethodPost, Path: "/rest/v1/optimal_move",
+		Summary: "Get the best move to make in a position.",
+		Request: OptimalMoveRequest{}, Response: OptimalMoveResponse{}},
+	{Method: http.MethodPost, Path: "/rest/v1/outcome_distribution",
+		Summary: "Get the distribution of final scores for every choice in a position.",
+		Request: OutcomeDistributionRequest{}, Response: OutcomeDistributionResponse{}},
+	{Method: http.MethodPost, Path: "/rest/v1/explain",
+		Summary: "Rank and explain every choice in a position.",
+		Request: ExplainRequest{}, Response: ExplainResponse{}},
+	{Method: http.MethodPost, Path: "/rest/v1/batch/optimal_move",
+		Summary: "Get the best move for many positions, as a stream of BatchResults in JSON Lines format.",
+		Request: BatchOptimalMoveRequest{}, Response: BatchResult{},
+		ContentType: "application/x-ndjson"},
+	{Method: http.MethodPost, Path: "/rest/v1/games",
+		Summary: "Start a new game session.",
+		Request: CreateGameRequest{}, Response: GameSession{}},
+	{Method: http.MethodGet, Path: "/rest/v1/games/{id}",
+		Summary:  "Get the current state of a game session.",
+		Response: GameSession{}},
+	{Method: http.MethodDelete, Path: "/rest/v1/games/{id}",
+		Summary: "End a game session.",
+		Status:  http.StatusNoContent},
+	{Method: http.MethodPost, Path: "/rest/v1/games/{id}/roll",
+		Summary: "Record a roll of the dice.",
+		Request: RollRequest{}, Response: GameSession{}},
+	{Method: http.MethodPost, Path: "/rest/v1/games/{id}/hold",
+		Summary: "Hold dice for the next roll.",
+		Request: HoldRequest{}, Response: GameSession{}},
+	{Method: http.MethodPost, Path: "/rest/v1/games/{id}/fill",
+		Summary: "Play the current dice in a box.",
+		Request: FillRequest{}, Response: GameSession{}},
+	{Method: http.MethodPost, Path: "/rest/v1/games/{id}/undo",
+		Summary:  "Undo the last roll, hold or fill.",
+		Response: GameSession{}},
+	{Method: http.MethodGet, Path: "/rest/v1/games/{id}/advice",
+		Summary:  "Get the best move in a game session.",
+		Response: OptimalMoveResponse{}},
+	{Method: http.MethodGet, Path: "/rest/v1/live",
+		Summary: "Upgrade to a WebSocket that streams LiveAdviceRequests " +
+			"and LiveAdviceResponses as JSON text messages.",
+		Status: http.StatusSwitchingProtocols},
+	{Method: http.MethodGet, Path: "/healthz",
+		Summary:  "Get the status of the server.",
+		Response: ServerStatus{}},
+	{Method: http.MethodGet, Path: "/readyz",
+		Summary:  "Get the status of the server. Fails with 503 until it is ready.",
+		Response: ServerStatus{},
+		Errors:   map[int]interface{}{http.StatusServiceUnavailable: ServerStatus{}}},
+	{Method: http.MethodPost, Path: "/admin/reload",
+		Summary: "Reload strategy tables (only if the server is run with -enable_admin).",
+		Request: ReloadRequest{}, Response: ReloadResponse{}},
+	{Method: http.MethodGet, Path: "/debug/game_state",
+		Summary:  "Decode a GameState, given in GameState notation (game) or by its ID (id).",
+		Response: GameStateInfo{}, Query: []string{"game", "id"}},
+	{Method: http.MethodGet, Path: "/openapi.json",
+		Summary:  "Get this OpenAPI document.",
+		Response: map[string]interface{}{}},
+}
+
+// schemaTypes are types that are not used directly by any operation
+// (e.g. the messages of the live advice WebSocket), but are included
+// in the document for reference.
+var schemaTypes = []interface{}{
+	LiveAdviceResponse{},
+}
+
+// requestSchemaTypes are like schemaTypes, but are sent by clients.
+var requestSchemaTypes = []interface{}{
+	LiveAdviceRequest{},
+}
+
+// enums are the allowed values of API types with a fixed set of values.
+var enums = map[reflect.Type][]interface{}{
+	reflect.TypeOf(Objective("")): {
+		ObjectiveExpectedValue, ObjectiveProbabilityToBeat, ObjectiveExpectedWork,
+	},
+	reflect.TypeOf(ChoiceCategory("")): {
+		CategoryKeepAll, CategoryRerollAll, CategoryYahtzee, CategoryOfAKind,
+		CategoryFullHouse, CategorySmallStraight, CategoryLargeStraight,
+		CategoryUpperBonus, CategoryUpper, CategoryChance, CategoryOther,
+		CategoryScore, CategoryYahtzeeBonus, CategoryScratch,
+	},
+	reflect.TypeOf(yahtzee.Begin): {
+		yahtzee.Begin, yahtzee.Hold1, yahtzee.Hold2, yahtzee.FillBox,
+	},
+}
+
+// schema is an OpenAPI 3.0 schema object.
+type schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	AllOf                []*schema          `json:"allOf,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Enum                 []interface{}      `json:"enum,omitempty"`
+	Items                *schema            `json:"items,omitempty"`
+	Properties           map[string]*schema `json:"properties,omitempty"`
+	AdditionalProperties interface{}        `json:"additionalProperties,omitempty"`
+}
+
+type openAPIDocument struct {
+	OpenAPI    string                                  `json:"openapi"`
+	Info       openAPIInfo                             `json:"info"`
+	Paths      map[string]map[string]*openAPIOperation `json:"paths"`
+	Components openAPIComponents                       `json:"components"`
+}
+
+type openAPIInfo struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+type openAPIComponents struct {
+	Schemas map[string]*schema `json:"schemas"`
+}
+
+type openAPIOperation struct {
+	Summary     string                      `json:"summary"`
+	Parameters  []openAPIParameter          `json:"parameters,omitempty"`
+	RequestBody *openAPIBody                `json:"requestBody,omitempty"`
+	Responses   map[string]*openAPIResponse `json:"responses"`
+}
+
+type openAPIParameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required"`
+	Schema   *schema `json:"schema"`
+}
+
+type openAPIBody struct {
+	Required bool                         `json:"required,omitempty"`
+	Content  map[string]*openAPIMediaType `json:"content"`
+}
+
+type openAPIResponse struct {
+	Description string                       `json:"description"`
+	Content     map[string]*openAPIMediaType `json:"content,omitempty"`
+}
+
+type openAPIMediaType struct {
+	Schema *schema `json:"schema"`
+}
+
+var (
+	openAPIOnce sync.Once
+	openAPIJSON []byte
+)
+
+// OpenAPI serves the OpenAPI 3.0 document describing the API.
+func (ys *YahtzeeServer) OpenAPI(w http.ResponseWriter, r *http.Request) {
+	openAPIOnce.Do(func() {
+		var err error
+		openAPIJSON, err = json.MarshalIndent(newOpenAPIDocument(), "", "  ")
+		if err != nil {
+			panic(err)
+		}
+	})
+
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	if _, err := w.Write(openAPIJSON); err != nil {
+		glog.Warning(err)
+	}
+}
+
+// newOpenAPIDocument generates the OpenAPI document from operations.
+func newOpenAPIDocument() *openAPIDocument {
+	g := &schemaGenerator{schemas: make(map[string]*schema)}
+	doc := &openAPIDocument{
+		OpenAPI:    "3.0.3",
+		Info:       openAPIInfo{Title: "YAHTZEE", Version: "v1"},
+		Paths:      make(map[string]map[string]*openAPIOperation),
+		Components: openAPIComponents{Schemas: g.schemas},
+	}
+
+	errorResponse := &openAPIResponse{
+		Description: "The request failed.",
+		Content:     jsonContent(g.schemaOf(reflect.TypeOf(ErrorResponse{}))),
+	}
+
+	var requests []*schema
+	for _, op := range operations {
+		o := &openAPIOperation{
+			Summary:   op.Summary,
+			Responses: map[string]*openAPIResponse{"default": errorResponse},
+		}
+
+		if strings.Contains(op.Path, "{id}") {
+			o.Parameters = append(o.Parameters, openAPIParameter{
+				Name: "id", In: "path", Required: true,
+				Schema: &schema{Type: "string"},
+			})
+		}
+
+		for _, name := range op.Query {
+			o.Parameters = append(o.Parameters, openAPIParameter{
+				Name: name, In: "query",
+				Schema: &schema{Type: "string"},
+			})
+		}
+
+		if op.Request != nil {
+			s := g.schemaOf(reflect.TypeOf(op.Request))
+			requests = append(requests, s)
+			o.RequestBody = &openAPIBody{
+				Required: true,
+				Content:  jsonContent(s),
+			}
+		}
+
+		resp := &openAPIResponse{Description: http.StatusText(op.status())}
+		if op.Response != nil {
+			contentType := op.ContentType
+			if contentType == "" {
+				contentType = "application/json"
+			}
+			resp.Content = map[string]*openAPIMediaType{
+				contentType: {Schema: g.schemaOf(reflect.TypeOf(op.Response))},
+			}
+		}
+		o.Responses[strconv.Itoa(op.status())] = resp
+
+		for status, body := range op.Errors {
+			o.Responses[strconv.Itoa(status)] = &openAPIResponse{
+				Description: http.StatusText(status),
+				Content:     jsonContent(g.schemaOf(reflect.TypeOf(body))),
+			}
+		}
+
+		if doc.Paths[op.Path] == nil {
+			doc.Paths[op.Path] = make(map[string]*openAPIOperation)
+		}
+		doc.Paths[op.Path][strings.ToLower(op.Method)] = o
+	}
+
+	for _, v := range schemaTypes {
+		g.schemaOf(reflect.TypeOf(v))
+	}
+	for _, v := range requestSchemaTypes {
+		requests = append(requests, g.schemaOf(reflect.TypeOf(v)))
+	}
+
+	// The handlers ignore unknown fields in requests, so only the
+	// objects that are never sent by clients are closed.
+	opened := make(map[*schema]bool)
+	for _, s := range requests {
+		g.allowAdditionalProperties(s, opened)
+	}
+
+	return doc
+}
+
+func (op operation) status() int {
+	if op.Status == 0 {
+		return http.StatusOK
+	}
+
+	return op.Status
+}
+
+func jsonContent(s *schema) map[string]*openAPIMediaType {
+	return map[string]*openAPIMediaType{
+		"application/json": {Schema: s},
+	}
+}
+
+// schemaGenerator generates schemas for Go types, as they are encoded
+// by encoding/json. Named struct types are added to schemas and
+// referenced by name.
+type schemaGenerator struct {
+	schemas map[string]*schema
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func (g *schemaGenerator) schemaOf(t reflect.Type) *schema {
+	if values, ok := enums[t]; ok {
+		s := g.schemaOfKind(t)
+		s.Enum = values
+		return s
+	}
+
+	switch {
+	case t == timeType:
+		return &schema{Type: "string", Format: "date-time"}
+	case t.Kind() == reflect.Ptr:
+		return g.schemaOf(t.Elem())
+	case t.Kind() == reflect.Struct && t.Name() != "":
+		if _, ok := g.schemas[t.Name()]; !ok {
+			g.schemas[t.Name()] = nil // Placeholder for recursive types.
+			g.schemas[t.Name()] = g.structSchema(t)
+		}
+		return &schema{Ref: "#/components/schemas/" + t.Name()}
+	case t.Kind() == reflect.Struct:
+		return g.structSchema(t)
+	}
+
+	return g.schemaOfKind(t)
+}
+
+func (g *schemaGenerator) schemaOfKind(t reflect.Type) *schema {
+	switch t.Kind() {
+	case reflect.Bool:
+		return &schema{Type: "boolean"}
+	case reflect.String:
+		return &schema{Type: "string"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &schema{Type: "integer"}
+	case reflect.Float32:
+		return &schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &schema{Type: "number", Format: "double"}
+	case reflect.Slice:
+		// Nil slices are encoded as null.
+		return &schema{Type: "array", Items: g.schemaOf(t.Elem()), Nullable: true}
+	case reflect.Array:
+		return &schema{Type: "array", Items: g.schemaOf(t.Elem())}
+	case reflect.Map:
+		return &schema{Type: "object", AdditionalProperties: g.schemaOf(t.Elem()), Nullable: true}
+	case reflect.Interface:
+		return &schema{}
+	}
+
+	panic("unsupported type in API: " + t.String())
+}
+
+// allowAdditionalProperties allows additional properties in s and
+// every object schema it contains, unless they are already in opened.
+func (g *schemaGenerator) allowAdditionalProperties(s *schema, opened map[*schema]bool) {
+	if s == nil || opened[s] {
+		return
+	} else if s.Ref != "" {
+		g.allowAdditionalProperties(g.schemas[strings.TrimPrefix(s.Ref, "#/components/schemas/")], opened)
+		return
+	}
+
+	opened[s] = true
+	if s.AdditionalProperties == false {
+		s.AdditionalProperties = nil
+	} else if additional, ok := s.AdditionalProperties.(*schema); ok {
+		g.allowAdditionalProperties(additional, opened)
+	}
+
+	for _, fs := range s.Properties {
+		g.allowAdditionalProperties(fs, opened)
+	}
+	for _, sub := range s.AllOf {
+		g.allowAdditionalProperties(sub, opened)
+	}
+	g.allowAdditionalProperties(s.Items, opened)
+}
+
+// structSchema generates the schema of a struct, following the
+// encoding/json rules for field names, omitted and embedded fields.
+// Additional properties are not allowed, unless the struct is sent by
+// clients (see allowAdditionalProperties).
+func (g *schemaGenerator) structSchema(t reflect.Type) *schema {
+	s := &schema{
+		Type:                 "object",
+		Properties:           make(map[string]*schema),
+		AdditionalProperties: false,
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" || (field.PkgPath != "" && !field.Anonymous) {
+			continue
+		}
+
+		name := strings.Split(tag, ",")[0]
+		omitEmpty := strings.Contains(tag, ",omitempty")
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			embedded := g.structSchema(field.Type)
+			for name, fs := range embedded.Properties {
+				s.Properties[name] = fs
+			}
+			continue
+		} else if name == "" {
+			name = field.Name
+		}
+
+		fs := g.schemaOf(field.Type)
+		if field.Type.Kind() == reflect.Ptr && !omitEmpty {
+			// Nil pointers are encoded as null.
+			fs = &schema{AllOf: []*schema{fs}, Nullable: true}
+		}
+		s.Properties[name] = fs
+	}
+
+	return s
+}
@@ -0,0 +1,399 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/timpalpant/yahtzee"
+	"github.com/timpalpant/yahtzee/optimization"
+	"github.com/timpalpant/yahtzee/server/websocket"
+)
+
+// lateGame has only Chance and Yahtzee open, so that the strategy
+// tables can be computed quickly on demand.
+const lateGame = `{"Filled":[true,true,true,true,true,true,true,true,true,true,true,false,false],"UpperHalfScore":50}`
+
+// apiTestCase is a request to make to the API, whose response
+// is validated against the OpenAPI document.
+type apiTestCase struct {
+	// operation is the method and path of the operation in the document.
+	operation string
+	url       string
+	body      string
+	status    int
+}
+
+func newTestServer() *YahtzeeServer {
+	// The expected work table is only needed to compare the current
+	// game to a new game, which requires computing the entire table.
+	return NewYahtzeeServer(
+		optimization.NewStrategy(optimization.NewScoreDistribution()),
+		optimization.NewStrategy(optimization.NewExpectedValue()),
+		nil)
+}
+
+func newTestMux(ys *YahtzeeServer) *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/rest/v1/score", ys.GetScore)
+	mux.HandleFunc("/rest/v1/optimal_move", ys.OptimalMove)
+	mux.HandleFunc("/rest/v1/outcome_distribution", ys.OutcomeDistribution)
+	mux.HandleFunc("/rest/v1/explain", ys.Explain)
+	mux.HandleFunc("/rest/v1/batch/optimal_move", ys.BatchOptimalMove)
+	mux.HandleFunc("/rest/v1/games", ys.Games)
+	mux.HandleFunc("/rest/v1/games/", ys.Games)
+	mux.HandleFunc("/healthz", ys.Healthz)
+	mux.HandleFunc("/readyz", ys.Readyz)
+	mux.HandleFunc("/admin/reload", ys.ReloadTables)
+	mux.HandleFunc("/debug/game_state", ys.DebugGameState)
+	mux.HandleFunc("/openapi.json", ys.OpenAPI)
+	return mux
+}
+
+func TestResponsesMatchOpenAPI(t *testing.T) {
+	ys := newTestServer()
+	ys.SetTableDir(os.TempDir())
+	mux := newTestMux(ys)
+	doc := newOpenAPIDocument()
+
+	session := ys.sessions.create(0)
+	games := "/rest/v1/games/" + session.ID
+	// Advice for a new game would compute the entire strategy table,
+	// so it is requested for a game in the lateGame state.
+	late := newLateGameSession(t, ys)
+	testCases := []apiTestCase{
+		{"POST /rest/v1/score", "/rest/v1/score", `{"Dice":[6,6,6,1,2],"Box":5}`, 200},
+		{"POST /rest/v1/score", "/rest/v1/score", `{"Dice":[6,6,6,1],"Box":5}`, 400},
+		{"POST /rest/v1/optimal_move", "/rest/v1/optimal_move",
+			`{"GameState":` + lateGame + `,"TurnState":{"Step":1,"Dice":[1,2,3,6,6]}}`, 200},
+		{"POST /rest/v1/optimal_move", "/rest/v1/optimal_move",
+			`{"GameState":` + lateGame + `,"TurnState":{"Step":3,"Dice":[1,2,3,6,6]},"Objective":"expected_value"}`, 200},
+		{"POST /rest/v1/optimal_move", "/rest/v1/optimal_move",
+			`{"GameState":` + lateGame + `,"TurnState":{"Step":1,"Dice":[1,2,3,6,6]},"ScoreToBeat":40,"Objective":"probability_to_beat"}`, 200},
+		{"POST /rest/v1/optimal_move", "/rest/v1/optimal_move", `{"GameState":`, 400},
+		{"POST /rest/v1/outcome_distribution", "/rest/v1/outcome_distribution",
+			`{"GameState":` + lateGame + `,"TurnState":{"Step":2,"Dice":[1,2,3,6,6]},"Ranges":[{"Min":20,"Max":30}]}`, 200},
+		{"POST /rest/v1/outcome_distribution", "/rest/v1/outcome_distribution",
+			`{"GameState":` + lateGame + `,"TurnState":{"Step":1,"Dice":[1,2,3,6,6],"HeldDice":[6,6]}}`, 200},
+		{"POST /rest/v1/explain", "/rest/v1/explain",
+			`{"GameState":` + lateGame + `,"TurnState":{"Step":1,"Dice":[1,2,3,6,6]},"ScoreToBeat":20}`, 200},
+		{"POST /rest/v1/explain", "/rest/v1/explain",
+			`{"GameState":` + lateGame + `,"TurnState":{"Step":0,"Dice":[1,2,3,6,6]}}`, 400},
+		{"POST /rest/v1/batch/optimal_move", "/rest/v1/batch/optimal_move",
+			`[{"GameState":` + lateGame + `,"TurnState":{"Step":3,"Dice":[1,2,3,6,6]}},{"GameState":{}}]`, 200},
+		{"POST /rest/v1/games", "/rest/v1/games", `{"ScoreToBeat":200}`, 200},
+		{"POST /rest/v1/games/{id}/roll", games + "/roll", `{"Dice":[1,2,3,6,6]}`, 200},
+		{"POST /rest/v1/games/{id}/hold", games + "/hold", `{"HeldDice":[6,6]}`, 200},
+		{"POST /rest/v1/games/{id}/undo", games + "/undo", ``, 200},
+		{"POST /rest/v1/games/{id}/fill", games + "/fill", `{"Box":5}`, 200},
+		{"POST /rest/v1/games/{id}/fill", games + "/fill", `{"Box":5}`, 409},
+		{"GET /rest/v1/games/{id}", games, ``, 200},
+		{"GET /rest/v1/games/{id}/advice", "/rest/v1/games/" + late + "/advice", ``, 200},
+		{"GET /rest/v1/games/{id}/advice", "/rest/v1/games/unknown/advice", ``, 404},
+		{"DELETE /rest/v1/games/{id}", games, ``, 204},
+		{"GET /rest/v1/games/{id}", games, ``, 404},
+		{"GET /healthz", "/healthz", ``, 200},
+		{"GET /readyz", "/readyz", ``, 200},
+		{"POST /admin/reload", "/admin/reload", `{"Tables":{"expected_value":"nonexistent.gob.gz"}}`, 200},
+		{"POST /admin/reload", "/admin/reload", `{"Tables":{"expected_value":"/etc/passwd"}}`, 400},
+		{"POST /admin/reload", "/admin/reload", `{"Tables":{"unknown":"nonexistent.gob.gz"}}`, 400},
+		{"GET /debug/game_state", "/debug/game_state?game=filled%3DFives%2CSixes%2CYahtzee+uhs%3D42+bonus", ``, 200},
+		{"GET /debug/game_state", "/debug/game_state?game=filled%3DYahtzee+uhs%3D42+bonus", ``, 400},
+		{"GET /debug/game_state", "/debug/game_state?id=12288", ``, 200},
+		{"GET /debug/game_state", "/debug/game_state?game=bonus", ``, 400},
+		{"GET /openapi.json", "/openapi.json", ``, 200},
+	}
+
+	tested := make(map[string]bool)
+	for _, tc := range testCases {
+		tested[tc.operation] = true
+		op := doc.operation(tc.operation)
+		if op == nil {
+			t.Errorf("%v is not in the OpenAPI document", tc.operation)
+			continue
+		}
+
+		method := strings.Split(tc.operation, " ")[0]
+		req := httptest.NewRequest(method, tc.url, strings.NewReader(tc.body))
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		if w.Code != tc.status {
+			t.Errorf("%v %v: status = %d, expected %d: %v", method, tc.url, w.Code, tc.status, w.Body)
+			continue
+		}
+
+		if err := validateResponse(doc, op, w); err != nil {
+			t.Errorf("%v %v: %v", method, tc.url, err)
+		}
+	}
+
+	if err := validateLiveAdvice(doc, ys); err != nil {
+		t.Errorf("GET /rest/v1/live: %v", err)
+	}
+	tested["GET /rest/v1/live"] = true
+
+	for _, op := range operations {
+		if key := op.Method + " " + op.Path; !tested[key] {
+			t.Errorf("%v is not tested", key)
+		}
+	}
+}
+
+// newLateGameSession creates a session in the lateGame state, after
+// the first roll, and returns its ID.
+func newLateGameSession(t *testing.T, ys *YahtzeeServer) string {
+	var gs GameState
+	if err := json.Unmarshal([]byte(lateGame), &gs); err != nil {
+		t.Fatal(err)
+	}
+
+	id := ys.sessions.create(0).ID
+	_, err := ys.sessions.update(id, func(s *session) error {
+		s.current = yahtzee.Turn{Scorecard: yahtzee.Scorecard{Game: gs.ToYahtzeeGameState()}}
+		return s.roll([]int{1, 2, 3, 6, 6})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return id
+}
+
+// validateLiveAdvice checks that a message from the live advice
+// WebSocket matches the LiveAdviceResponse schema in the document.
+func validateLiveAdvice(doc *openAPIDocument, ys *YahtzeeServer) error {
+	ts := httptest.NewServer(http.HandlerFunc(ys.LiveAdvice))
+	defer ts.Close()
+
+	conn, err := websocket.Dial(context.Background(), ts.URL)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	req := `{"GameState":` + lateGame + `,"TurnState":{"Step":1,"Dice":[1,2,3,6,6]},"ScoreToBeat":30}`
+	if err := conn.WriteMessage([]byte(req)); err != nil {
+		return err
+	}
+
+	msg, err := conn.ReadMessage()
+	if err != nil {
+		return err
+	}
+
+	var resp LiveAdviceResponse
+	if err := json.Unmarshal(msg, &resp); err != nil {
+		return err
+	} else if resp.Error != nil {
+		return resp.Error
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(msg, &value); err != nil {
+		return err
+	}
+
+	s := &schema{Ref: "#/components/schemas/LiveAdviceResponse"}
+	return validateSchema(doc, s, value, "message")
+}
+
+func TestRequestSchemasAllowUnknownFields(t *testing.T) {
+	doc := newOpenAPIDocument()
+	testCases := []struct {
+		name     string
+		expected interface{}
+	}{
+		// Sent by clients, and shared with responses.
+		{"OptimalMoveRequest", nil},
+		{"GameState", nil},
+		{"TurnState", nil},
+		{"LiveAdviceRequest", nil},
+		// Only sent by the server.
+		{"OptimalMoveResponse", false},
+		{"LiveAdviceResponse", false},
+		{"ServerStatus", false},
+	}
+
+	for _, tc := range testCases {
+		s, ok := doc.Components.Schemas[tc.name]
+		if !ok {
+			t.Errorf("%v is not in the OpenAPI document", tc.name)
+		} else if s.AdditionalProperties != tc.expected {
+			t.Errorf("%v: additionalProperties = %v, expected %v",
+				tc.name, s.AdditionalProperties, tc.expected)
+		}
+	}
+}
+
+func TestUnavailableResponsesMatchOpenAPI(t *testing.T) {
+	ys := NewYahtzeeServer(nil, nil, nil)
+	mux := newTestMux(ys)
+	doc := newOpenAPIDocument()
+
+	testCases := []apiTestCase{
+		{"GET /readyz", "/readyz", ``, 503},
+		{"POST /rest/v1/optimal_move", "/rest/v1/optimal_move",
+			`{"GameState":` + lateGame + `,"TurnState":{"Step":1,"Dice":[1,2,3,6,6]}}`, 503},
+	}
+
+	for _, tc := range testCases {
+		method := strings.Split(tc.operation, " ")[0]
+		req := httptest.NewRequest(method, tc.url, strings.NewReader(tc.body))
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		if w.Code != tc.status {
+			t.Errorf("%v %v: status = %d, expected %d: %v", method, tc.url, w.Code, tc.status, w.Body)
+			continue
+		}
+
+		if err := validateResponse(doc, doc.operation(tc.operation), w); err != nil {
+			t.Errorf("%v %v: %v", method, tc.url, err)
+		}
+	}
+}
+
+// operation returns the operation with the given method and path,
+// e.g. "GET /healthz", or nil if it is not in the document.
+func (doc *openAPIDocument) operation(key string) *openAPIOperation {
+	parts := strings.SplitN(key, " ", 2)
+	return doc.Paths[parts[1]][strings.ToLower(parts[0])]
+}
+
+// validateResponse checks that the recorded response matches
+// the schema of its status code in the document.
+func validateResponse(doc *openAPIDocument, op *openAPIOperation, w *httptest.ResponseRecorder) error {
+	resp, ok := op.Responses[strconv.Itoa(w.Code)]
+	if !ok {
+		resp = op.Responses["default"]
+		if w.Code < 400 || resp == nil {
+			return fmt.Errorf("status %d is not documented", w.Code)
+		}
+	}
+
+	if len(resp.Content) == 0 {
+		if w.Body.Len() != 0 {
+			return fmt.Errorf("unexpected body: %v", w.Body)
+		}
+		return nil
+	}
+
+	contentType := strings.Split(w.Header().Get("Content-Type"), ";")[0]
+	media, ok := resp.Content[contentType]
+	if !ok {
+		return fmt.Errorf("content type %q is not documented", contentType)
+	}
+
+	if contentType != "application/x-ndjson" {
+		var value interface{}
+		if err := json.Unmarshal(w.Body.Bytes(), &value); err != nil {
+			return err
+		}
+		return validateSchema(doc, media.Schema, value, "body")
+	}
+
+	scanner := bufio.NewScanner(w.Body)
+	for i := 0; scanner.Scan(); i++ {
+		var value interface{}
+		if err := json.Unmarshal(scanner.Bytes(), &value); err != nil {
+			return err
+		} else if err := validateSchema(doc, media.Schema, value, fmt.Sprintf("line %d", i)); err != nil {
+			return err
+		}
+	}
+
+	return scanner.Err()
+}
+
+// validateSchema checks that a decoded JSON value matches the schema.
+func validateSchema(doc *openAPIDocument, s *schema, value interface{}, path string) error {
+	if s.Ref != "" {
+		name := strings.TrimPrefix(s.Ref, "#/components/schemas/")
+		ref, ok := doc.Components.Schemas[name]
+		if !ok {
+			return fmt.Errorf("%v: unknown schema %v", path, s.Ref)
+		}
+		return validateSchema(doc, ref, value, path)
+	}
+
+	if value == nil {
+		if !s.Nullable && s.Type != "" {
+			return fmt.Errorf("%v: null is not allowed", path)
+		}
+		return nil
+	}
+
+	for _, sub := range s.AllOf {
+		if err := validateSchema(doc, sub, value, path); err != nil {
+			return err
+		}
+	}
+
+	if len(s.Enum) > 0 {
+		enum, _ := json.Marshal(s.Enum)
+		v, _ := json.Marshal(value)
+		if !strings.Contains(string(enum), string(v)) {
+			return fmt.Errorf("%v: %s is not one of %s", path, v, enum)
+		}
+	}
+
+	switch s.Type {
+	case "":
+		return nil
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("%v: expected boolean, got %v", path, value)
+		}
+	case "string":
+		if _, ok := value.(string); !ok {
+			return fmt.Errorf("%v: expected string, got %v", path, value)
+		}
+	case "integer", "number":
+		x, ok := value.(float64)
+		if !ok {
+			return fmt.Errorf("%v: expected %v, got %v", path, s.Type, value)
+		} else if s.Type == "integer" && x != math.Trunc(x) {
+			return fmt.Errorf("%v: expected integer, got %v", path, x)
+		}
+	case "array":
+		items, ok := value.([]interface{})
+		if !ok {
+			return fmt.Errorf("%v: expected array, got %v", path, value)
+		}
+		for i, item := range items {
+			if err := validateSchema(doc, s.Items, item, fmt.Sprintf("%v[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	case "object":
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%v: expected object, got %v", path, value)
+		}
+		for name, v := range obj {
+			fieldPath := path + "." + name
+			if fs, ok := s.Properties[name]; ok {
+				if err := validateSchema(doc, fs, v, fieldPath); err != nil {
+					return err
+				}
+			} else if additional, ok := s.AdditionalProperties.(*schema); ok {
+				if err := validateSchema(doc, additional, v, fieldPath); err != nil {
+					return err
+				}
+			} else if s.AdditionalProperties == false {
+				return fmt.Errorf("%v: unexpected property", fieldPath)
+			}
+		}
+	default:
+		return fmt.Errorf("%v: unknown schema type %v", path, s.Type)
+	}
+
+	return nil
+}
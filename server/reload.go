@@ -0,0 +1,228 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"path/filepath"
+	"time"
+
+	"github.com/golang/glog"
+
+	"github.com/timpalpant/yahtzee"
+	"github.com/timpalpant/yahtzee/optimization"
+)
+
+// DefaultMaxE0Change is the largest relative change in E_0 that is
+// accepted when a table is reloaded, unless the reload is forced.
+const DefaultMaxE0Change = 0.05
+
+// ReloadTables is the admin endpoint that reloads strategy tables
+// (see ReloadRequest). It responds once all tables have been reloaded,
+// which may take several minutes. Tables may only be loaded from
+// files in the directory set with SetTableDir.
+func (ys *YahtzeeServer) ReloadTables(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, methodNotAllowed(r.Method))
+		return
+	}
+
+	req := ReloadRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, invalidJSON(err))
+		return
+	}
+
+	for name, file := range req.Tables {
+		if !isTableName(name) {
+			writeError(w, badRequest(ErrCodeInvalidTable, "Tables",
+				"unknown table: %v", name))
+			return
+		}
+
+		filename, err := ys.tableFile(file)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		req.Tables[name] = filename
+	}
+
+	writeJSON(w, ys.Reload(&req))
+}
+
+// Reload loads new versions of the requested tables alongside the
+// current ones, validates them, and then swaps them in. Requests that
+// are in progress continue to use the previous tables.
+//
+// If no tables are requested, all tables are reloaded from the file
+// they were last loaded from.
+func (ys *YahtzeeServer) Reload(req *ReloadRequest) *ReloadResponse {
+	ys.reloadMu.Lock()
+	defer ys.reloadMu.Unlock()
+
+	files := req.Tables
+	if len(files) == 0 {
+		files = ys.tableFiles()
+	}
+
+	maxChange := req.MaxE0Change
+	if maxChange <= 0 {
+		maxChange = DefaultMaxE0Change
+	}
+
+	resp := &ReloadResponse{}
+	for _, name := range TableNames {
+		filename, ok := files[name]
+		if !ok {
+			continue
+		}
+
+		change, err := ys.reloadTable(name, filename, maxChange, req.Force)
+		result := ReloadResult{Name: name, File: filename, E0Change: change}
+		if err != nil {
+			glog.Errorf("Error reloading %v table: %v", name, err)
+			result.Error = reportError(err)
+		}
+
+		resp.Tables = append(resp.Tables, result)
+	}
+
+	return resp
+}
+
+// tableFile returns the path of a file in the table directory. Only
+// plain file names are accepted, so that requests cannot load files
+// from anywhere else on the server.
+func (ys *YahtzeeServer) tableFile(file string) (string, error) {
+	ys.mu.RLock()
+	dir := ys.tableDir
+	ys.mu.RUnlock()
+
+	if dir == "" {
+		return "", badRequest(ErrCodeInvalidTable, "Tables",
+			"the server has no table directory to load %v from", file)
+	} else if file == "" || file == "." || file == ".." || file != filepath.Base(file) {
+		return "", badRequest(ErrCodeInvalidTable, "Tables",
+			"invalid file: %q, must be the name of a file in the table directory", file)
+	}
+
+	return filepath.Join(dir, file), nil
+}
+
+// tableFiles returns the file each table was last loaded from.
+func (ys *YahtzeeServer) tableFiles() map[string]string {
+	ys.mu.RLock()
+	defer ys.mu.RUnlock()
+
+	files := make(map[string]string)
+	for name, status := range ys.status {
+		if status.filename != "" {
+			files[name] = status.filename
+		}
+	}
+
+	return files
+}
+
+func (ys *YahtzeeServer) reloadTable(name, filename string, maxChange float64, force bool) (float64, error) {
+	glog.Infof("Reloading %v table from %v", name, filename)
+	start := time.Now()
+	strat, err := LoadTable(name, filename)
+	if err != nil {
+		return 0, badRequest(ErrCodeInvalidTable, "Tables",
+			"error loading %v table: %v", name, err)
+	}
+
+	newE0, _ := strat.Lookup(yahtzee.NewGame())
+	if err := validateE0(newE0); err != nil {
+		return 0, badRequest(ErrCodeInvalidTable, "Tables",
+			"invalid %v table: %v", name, err)
+	}
+
+	var change float64
+	current, err := ys.getTables(name)
+	if err == nil {
+		oldE0, _ := current.get(name).Lookup(yahtzee.NewGame())
+		change = e0Change(oldE0, newE0)
+		glog.Infof("E_0 of %v table changed by %.4f", name, change)
+		if change > maxChange && !force {
+			return change, badRequest(ErrCodeInvalidTable, "Tables",
+				"E_0 of %v table changed by %.4f, more than %.4f", name, change, maxChange)
+		}
+	}
+
+	loadTime := time.Since(start)
+	glog.Infof("Reloaded %v table in %v", name, loadTime)
+	ys.setTable(name, strat, &tableStatus{
+		state:    TableLoaded,
+		filename: filename,
+		loadTime: loadTime,
+	})
+
+	return change, nil
+}
+
+// validateE0 checks that the value of a new game is a valid number.
+func validateE0(e0 optimization.GameResult) error {
+	var values []float32
+	switch e0 := e0.(type) {
+	case optimization.ExpectedValue:
+		values = []float32{float32(e0)}
+	case optimization.ScoreDistribution:
+		values = e0
+	case optimization.ExpectedWork:
+		values = e0.Values
+	}
+
+	for _, v := range values {
+		if math.IsNaN(float64(v)) || math.IsInf(float64(v), 0) || v < 0 {
+			return fmt.Errorf("E_0 contains invalid value %v", v)
+		}
+	}
+
+	return nil
+}
+
+// e0Change returns the largest relative change between the values
+// of a new game in two versions of a table. For score distributions,
+// the change in probability of achieving each score is used.
+func e0Change(oldE0, newE0 optimization.GameResult) float64 {
+	var oldValues, newValues []float32
+	relative := true
+	switch oldE0 := oldE0.(type) {
+	case optimization.ExpectedValue:
+		oldValues = []float32{float32(oldE0)}
+		newValues = []float32{float32(newE0.(optimization.ExpectedValue))}
+	case optimization.ScoreDistribution:
+		oldValues = oldE0
+		newValues = newE0.(optimization.ScoreDistribution)
+		relative = false
+	case optimization.ExpectedWork:
+		oldValues = oldE0.Values
+		newValues = newE0.(optimization.ExpectedWork).Values
+	}
+
+	var maxChange float64
+	for i, oldValue := range oldValues {
+		change := math.Abs(float64(newValues[i] - oldValue))
+		if relative && oldValue != 0 {
+			change /= math.Abs(float64(oldValue))
+		}
+
+		maxChange = math.Max(maxChange, change)
+	}
+
+	return maxChange
+}
+
+func isTableName(name string) bool {
+	for _, tableName := range TableNames {
+		if name == tableName {
+			return true
+		}
+	}
+
+	return false
+}
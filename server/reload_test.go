@@ -0,0 +1,103 @@
+package server
+
+import (
+	"encoding/gob"
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	gzip "github.com/klauspost/pgzip"
+
+	"github.com/timpalpant/yahtzee"
+	"github.com/timpalpant/yahtzee/optimization"
+)
+
+// writeTable writes a strategy table file with the value
+// of a new game only.
+func writeTable(t *testing.T, filename string, e0 optimization.GameResult) {
+	f, err := os.Create(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	gzw := gzip.NewWriter(f)
+	// The fields match the entries written by Strategy.SaveToFile.
+	entry := struct {
+		Key   uint
+		Value optimization.GameResult
+	}{uint(yahtzee.NewGame()), e0}
+	if err := gob.NewEncoder(gzw).Encode(entry); err != nil {
+		t.Fatal(err)
+	}
+	if err := gzw.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReloadTablesRestrictsFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tables")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	writeTable(t, filepath.Join(dir, "ev.gob.gz"), optimization.ExpectedValue(254.59))
+
+	testCases := []struct {
+		tableDir string
+		file     string
+		status   int
+	}{
+		{"", "ev.gob.gz", 400},
+		{dir, "ev.gob.gz", 200},
+		{dir, filepath.Join(dir, "ev.gob.gz"), 400},
+		{dir, "../ev.gob.gz", 400},
+		{dir, "sub/ev.gob.gz", 400},
+		{dir, "..", 400},
+		{dir, "", 400},
+	}
+
+	for _, tc := range testCases {
+		ys := newTestServer()
+		ys.SetTableDir(tc.tableDir)
+		body := `{"Tables":{"expected_value":"` + tc.file + `"}}`
+		r := httptest.NewRequest("POST", "/admin/reload", strings.NewReader(body))
+		w := httptest.NewRecorder()
+		ys.ReloadTables(w, r)
+		if w.Code != tc.status {
+			t.Errorf("reload of %q from %q: status = %d, expected %d: %v",
+				tc.file, tc.tableDir, w.Code, tc.status, w.Body)
+		} else if w.Code != 200 && !strings.Contains(w.Body.String(), ErrCodeInvalidTable) {
+			t.Errorf("reload of %q from %q: expected %v error, got %v",
+				tc.file, tc.tableDir, ErrCodeInvalidTable, w.Body)
+		} else if w.Code == 200 && strings.Contains(w.Body.String(), `"Error"`) {
+			t.Errorf("reload of %q from %q failed: %v", tc.file, tc.tableDir, w.Body)
+		}
+	}
+}
+
+func TestReloadWaitsForBackgroundLoad(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tables")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "ev.gob.gz")
+	writeTable(t, filename, optimization.ExpectedValue(254.59))
+
+	ys := newTestServer()
+	ys.LoadTableInBackground(TableExpectedValue, filename)
+	resp := ys.Reload(&ReloadRequest{})
+	if len(resp.Tables) != 1 || resp.Tables[0].Error != nil || resp.Tables[0].E0Change != 0 {
+		t.Errorf("Reload() = %+v, expected the loaded table to be reloaded without change", resp)
+	}
+
+	for _, status := range ys.serverStatus().Tables {
+		if status.Name == TableExpectedValue && status.State != TableLoaded {
+			t.Errorf("%v table is %v after reload, expected %v", status.Name, status.State, TableLoaded)
+		}
+	}
+}
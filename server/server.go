@@ -2,10 +2,12 @@ package server
 
 import (
 	"encoding/json"
-	"fmt"
 	"html/template"
 	"math"
 	"net/http"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/golang/glog"
 
@@ -14,32 +16,110 @@ import (
 )
 
 type YahtzeeServer struct {
-	highScoreStrat     *optimization.Strategy
-	expectedScoreStrat *optimization.Strategy
-	expectedWorkStrat  *optimization.Strategy
+	mu     sync.RWMutex
+	tables tables
+	status map[string]*tableStatus
+	// reloadMu ensures that only one reload is in progress at a time,
+	// and that reloads wait for tables that are loading in the background
+	// (which hold a read lock, so that they may load in parallel).
+	reloadMu sync.RWMutex
+	// tableDir is the directory that /admin/reload may load tables from.
+	tableDir string
+	// allowedOrigins are the other origins whose web pages may connect
+	// to /rest/v1/live.
+	allowedOrigins []string
+
+	sessions *sessionStore
+
+	assets    assets
+	templates *template.Template
 }
 
+// NewYahtzeeServer creates a server with the given strategy tables.
+// Any of the tables may be nil, and loaded later with LoadTableInBackground.
 func NewYahtzeeServer(highScoreStrat, expectedScoreStrat, expectedWorkStrat *optimization.Strategy) *YahtzeeServer {
-	return &YahtzeeServer{highScoreStrat, expectedScoreStrat, expectedWorkStrat}
+	ys := &YahtzeeServer{
+		status:    make(map[string]*tableStatus, len(TableNames)),
+		sessions:  newSessionStore(DefaultSessionTTL),
+		templates: template.Must(assets{}.parseTemplates()),
+	}
+	ys.tables.results = newResultCache(DefaultResultCacheBytes)
+
+	strats := map[string]*optimization.Strategy{
+		TableExpectedValue:     expectedScoreStrat,
+		TableScoreDistribution: highScoreStrat,
+		TableExpectedWork:      expectedWorkStrat,
+	}
+	for name, strat := range strats {
+		status := &tableStatus{state: TableUnavailable}
+		if strat != nil {
+			status.state = TableLoaded
+		}
+		ys.setTable(name, strat, status)
+	}
+
+	return ys
 }
 
-func (ys *YahtzeeServer) Index(w http.ResponseWriter, r *http.Request) {
-	t, err := template.ParseFiles("templates/index.html")
-	if err != nil {
-		glog.Error(err)
-		w.WriteHeader(http.StatusInternalServerError)
+// SetSessionTTL sets how long idle game sessions are kept before they expire.
+func (ys *YahtzeeServer) SetSessionTTL(ttl time.Duration) {
+	ys.sessions.setTTL(ttl)
+}
+
+// SetTableDir sets the directory that tables may be reloaded from with
+// ReloadTables. Unless it is set, ReloadTables only reloads the files
+// that the tables were loaded from.
+func (ys *YahtzeeServer) SetTableDir(dir string) {
+	ys.mu.Lock()
+	defer ys.mu.Unlock()
+	ys.tableDir = dir
+}
+
+// SetAllowedOrigins sets the origins (e.g. "https://example.com") of
+// other sites whose web pages may connect to LiveAdvice. Pages served
+// by this server may always connect.
+func (ys *YahtzeeServer) SetAllowedOrigins(origins []string) {
+	ys.mu.Lock()
+	defer ys.mu.Unlock()
+	ys.allowedOrigins = origins
+}
+
+// SetResultCacheBytes limits the (estimated) memory used by the turn
+// outcomes that are cached to speed up repeated requests. If maxBytes
+// is 0, nothing is cached.
+func (ys *YahtzeeServer) SetResultCacheBytes(maxBytes int64) {
+	ys.tables.results.setMaxBytes(maxBytes)
+}
+
+// Healthz reports the status of the server. It always succeeds
+// while the server is running.
+func (ys *YahtzeeServer) Healthz(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, ys.serverStatus())
+}
+
+// Readyz reports the status of the server, and fails with 503 Service
+// Unavailable until the server is ready to serve requests.
+func (ys *YahtzeeServer) Readyz(w http.ResponseWriter, r *http.Request) {
+	status := ys.serverStatus()
+	if !status.Ready {
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		if err := json.NewEncoder(w).Encode(status); err != nil {
+			glog.Warning(err)
+		}
 		return
 	}
 
-	t.Execute(w, struct{}{})
+	writeJSON(w, status)
 }
 
 func (ys *YahtzeeServer) GetScore(w http.ResponseWriter, r *http.Request) {
 	req := GetScoreRequest{}
-	err := json.NewDecoder(r.Body).Decode(&req)
-	if err != nil {
-		glog.Warning(err)
-		w.WriteHeader(http.StatusBadRequest)
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, invalidJSON(err))
+		return
+	} else if err := req.validate(); err != nil {
+		writeError(w, err)
 		return
 	}
 
@@ -47,55 +127,170 @@ func (ys *YahtzeeServer) GetScore(w http.ResponseWriter, r *http.Request) {
 	roll := yahtzee.NewRollFromDice(req.Dice)
 	score := box.Score(roll)
 
-	resp := GetScoreResponse{Score: score}
-	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
-	w.WriteHeader(http.StatusOK)
-	if err := json.NewEncoder(w).Encode(resp); err != nil {
-		glog.Warning(err)
-	}
+	writeJSON(w, GetScoreResponse{Score: score})
 }
 
 func (ys *YahtzeeServer) OptimalMove(w http.ResponseWriter, r *http.Request) {
 	req := &OptimalMoveRequest{}
-	err := json.NewDecoder(r.Body).Decode(req)
-	if err != nil {
-		glog.Warning(err)
-		w.WriteHeader(http.StatusBadRequest)
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		writeError(w, invalidJSON(err))
 		return
 	}
 
-	resp, err := ys.getOptimalMove(req)
+	resp, err := ys.ComputeOptimalMove(req)
 	if err != nil {
-		glog.Warning(err)
-		w.WriteHeader(http.StatusBadRequest)
+		writeError(w, err)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
-	w.WriteHeader(http.StatusOK)
-	if err := json.NewEncoder(w).Encode(resp); err != nil {
-		glog.Warning(err)
-	}
+	writeJSON(w, resp)
 }
 
 // OutcomeDistribution returns the probability of achieving a certain score
 // given the current game state.
 func (ys *YahtzeeServer) OutcomeDistribution(w http.ResponseWriter, r *http.Request) {
 	req := &OutcomeDistributionRequest{}
-	err := json.NewDecoder(r.Body).Decode(req)
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		writeError(w, invalidJSON(err))
+		return
+	}
+
+	resp, err := ys.ComputeOutcomeDistribution(req)
 	if err != nil {
-		glog.Warning(err)
-		w.WriteHeader(http.StatusBadRequest)
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, resp)
+}
+
+// Games implements the stateful game session endpoints:
+//
+//	POST   /rest/v1/games             Start a new game (CreateGameRequest).
+//	GET    /rest/v1/games/{id}        Get the current state of the game.
+//	DELETE /rest/v1/games/{id}        End the game.
+//	POST   /rest/v1/games/{id}/roll   Record a roll of the dice (RollRequest).
+//	POST   /rest/v1/games/{id}/hold   Hold dice for the next roll (HoldRequest).
+//	POST   /rest/v1/games/{id}/fill   Play the current dice in a box (FillRequest).
+//	POST   /rest/v1/games/{id}/undo   Undo the last roll, hold or fill.
+//	GET    /rest/v1/games/{id}/advice Get the optimal move (OptimalMoveResponse).
+//
+// All endpoints other than advice and delete return the updated GameSession.
+func (ys *YahtzeeServer) Games(w http.ResponseWriter, r *http.Request) {
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, "/rest/v1/games"), "/")
+	parts := strings.Split(path, "/")
+	id, action := parts[0], ""
+	if len(parts) > 2 {
+		writeError(w, notFound("unknown endpoint: %v", r.URL.Path))
+		return
+	} else if len(parts) == 2 {
+		action = parts[1]
+	}
+
+	switch {
+	case id == "" && r.Method == http.MethodPost:
+		req := CreateGameRequest{}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, invalidJSON(err))
+			return
+		} else if err := validateScoreToBeat(req.ScoreToBeat, "ScoreToBeat"); err != nil {
+			writeError(w, err)
+			return
+		}
+
+		writeJSON(w, ys.sessions.create(req.ScoreToBeat))
+	case id == "":
+		writeError(w, methodNotAllowed(r.Method))
+	case action == "" && r.Method == http.MethodGet:
+		ys.updateSession(w, id, func(s *session) error { return nil })
+	case action == "" && r.Method == http.MethodDelete:
+		if err := ys.sessions.delete(id); err != nil {
+			writeError(w, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	case action == "advice" && r.Method == http.MethodGet:
+		ys.sessionAdvice(w, id)
+	case action != "roll" && action != "hold" && action != "fill" && action != "undo":
+		writeError(w, notFound("unknown endpoint: %v", r.URL.Path))
+	case r.Method != http.MethodPost:
+		writeError(w, methodNotAllowed(r.Method))
+	case action == "roll":
+		req := RollRequest{}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, invalidJSON(err))
+			return
+		}
+
+		ys.updateSession(w, id, func(s *session) error { return s.roll(req.Dice) })
+	case action == "hold":
+		req := HoldRequest{}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, invalidJSON(err))
+			return
+		}
+
+		ys.updateSession(w, id, func(s *session) error { return s.hold(req.HeldDice) })
+	case action == "fill":
+		req := FillRequest{}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, invalidJSON(err))
+			return
+		}
+
+		ys.updateSession(w, id, func(s *session) error { return s.fill(req.Box) })
+	case action == "undo":
+		ys.updateSession(w, id, func(s *session) error { return s.undo() })
+	}
+}
+
+func (ys *YahtzeeServer) updateSession(w http.ResponseWriter, id string, fn func(s *session) error) {
+	gs, err := ys.sessions.update(id, fn)
+	if err != nil {
+		writeError(w, err)
 		return
 	}
 
-	resp, err := ys.getOutcomes(req)
+	writeJSON(w, gs)
+}
+
+func (ys *YahtzeeServer) sessionAdvice(w http.ResponseWriter, id string) {
+	gs, err := ys.sessions.update(id, func(s *session) error { return nil })
 	if err != nil {
-		glog.Warning(err)
-		w.WriteHeader(http.StatusBadRequest)
+		writeError(w, err)
+		return
+	} else if gs.GameOver {
+		writeError(w, invalidMove("game is over"))
+		return
+	}
+
+	// Advice is based on the score needed over the remaining turns.
+	scoreToBeat := gs.ScoreToBeat - gs.Scorecard.Total
+	if gs.ScoreToBeat <= 0 || scoreToBeat < 0 {
+		scoreToBeat = 0
+	}
+
+	// After dice are held, the advice is for the position after the hold.
+	turnState := gs.TurnState
+	turnState.HeldDice = gs.HeldDice
+	req := &OptimalMoveRequest{
+		GameState:    gs.GameState,
+		TurnState:    turnState,
+		ScoreToBeat:  scoreToBeat,
+		CurrentScore: gs.Scorecard.Total,
+	}
+
+	resp, err := ys.ComputeOptimalMove(req)
+	if err != nil {
+		writeError(w, err)
 		return
 	}
 
+	writeJSON(w, resp)
+}
+
+func writeJSON(w http.ResponseWriter, resp interface{}) {
 	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
 	w.WriteHeader(http.StatusOK)
 	if err := json.NewEncoder(w).Encode(resp); err != nil {
@@ -103,102 +298,243 @@ func (ys *YahtzeeServer) OutcomeDistribution(w http.ResponseWriter, r *http.Requ
 	}
 }
 
-func formatHoldChoices(expectedScores map[yahtzee.Roll]optimization.GameResult,
+func formatHoldChoices(req *OutcomeDistributionRequest,
+	expectedScores map[yahtzee.Roll]optimization.GameResult,
 	scoreDistributions map[yahtzee.Roll]optimization.GameResult) []HoldChoice {
 	holdChoices := make([]HoldChoice, 0, len(expectedScores))
 	for roll, es := range expectedScores {
-		expectedScore := float32(es.(optimization.ExpectedValue))
-		distribution := asDistribution(scoreDistributions[roll])
-		holdChoice := HoldChoice{roll.Dice(), expectedScore, distribution}
+		sd := scoreDistributions[roll].(optimization.ScoreDistribution)
+		holdChoice := HoldChoice{
+			HeldDice:               roll.Dice(),
+			ExpectedFinalScore:     float32(es.(optimization.ExpectedValue)),
+			DistributionSummary:    req.summarize(sd),
+			FinalScoreDistribution: req.distribution(sd),
+		}
 		holdChoices = append(holdChoices, holdChoice)
 	}
 
 	return holdChoices
 }
 
-func formatFillChoices(expectedScores map[yahtzee.Box]optimization.GameResult,
+func formatFillChoices(req *OutcomeDistributionRequest,
+	expectedScores map[yahtzee.Box]optimization.GameResult,
 	scoreDistributions map[yahtzee.Box]optimization.GameResult) []FillChoice {
 	fillChoices := make([]FillChoice, 0, len(expectedScores))
 	for box, es := range expectedScores {
-		expectedScore := float32(es.(optimization.ExpectedValue))
-		distribution := asDistribution(scoreDistributions[box])
-		fillChoice := FillChoice{int(box), expectedScore, distribution}
+		sd := scoreDistributions[box].(optimization.ScoreDistribution)
+		fillChoice := FillChoice{
+			BoxFilled:              int(box),
+			ExpectedFinalScore:     float32(es.(optimization.ExpectedValue)),
+			DistributionSummary:    req.summarize(sd),
+			FinalScoreDistribution: req.distribution(sd),
+		}
 		fillChoices = append(fillChoices, fillChoice)
 	}
 
 	return fillChoices
 }
 
-func (ys *YahtzeeServer) getOptimalMove(req *OptimalMoveRequest) (*OptimalMoveResponse, error) {
-	game := req.GameState.ToYahtzeeGameState()
-	roll := asRoll(req.TurnState.Dice)
-	glog.Infof("Computing optimal move for game: %v, roll: %v", game, roll)
+// ComputeOptimalMove returns the best move for the position in req,
+// as served by the optimal_move endpoint. Errors are *APIError.
+func (ys *YahtzeeServer) ComputeOptimalMove(req *OptimalMoveRequest) (*OptimalMoveResponse, error) {
+	if err := req.validate(); err != nil {
+		return nil, err
+	}
 
-	var opt *optimization.TurnOptimizer
-	if req.ScoreToBeat > 0 {
-		opt = optimization.NewTurnOptimizer(ys.expectedWorkStrat, game)
-	} else {
-		opt = optimization.NewTurnOptimizer(ys.expectedScoreStrat, game)
+	t, err := ys.getTables(req.requiredTables()...)
+	if err != nil {
+		return nil, err
 	}
 
+	game := req.GameState.ToYahtzeeGameState()
+	roll := asRoll(req.TurnState.Dice)
+	objective := req.objective()
+	glog.Infof("Computing optimal move for game: %v, roll: %v, objective: %v",
+		game, roll, objective)
+
+	table := objective.table()
 	resp := &OptimalMoveResponse{}
+	m := move{step: req.TurnState.Step, roll: roll}
 	switch req.TurnState.Step {
 	case yahtzee.Begin:
-		outcome := opt.GetOptimalTurnOutcome()
+		outcome := t.get(table).Compute(game)
 		resp.Value = gameResultValue(outcome, req.ScoreToBeat)
-	case yahtzee.Hold1:
-		outcomes := opt.GetHold1Outcomes(roll)
-		hold, score := bestHold(outcomes, req.ScoreToBeat)
-		resp.HeldDice = hold.Dice()
-		resp.Value = score
-	case yahtzee.Hold2:
-		outcomes := opt.GetHold2Outcomes(roll)
-		hold, score := bestHold(outcomes, req.ScoreToBeat)
-		resp.HeldDice = hold.Dice()
-		resp.Value = score
+	case yahtzee.Hold1, yahtzee.Hold2:
+		outcomes := t.holdOutcomes(table, game, req.TurnState.Step, roll)
+		if req.TurnState.HeldDice != nil {
+			// The dice to hold have already been chosen.
+			m.held = asRoll(req.TurnState.HeldDice)
+			resp.Value = gameResultValue(outcomes[m.held], req.ScoreToBeat)
+		} else {
+			m.held, resp.Value = bestHold(outcomes, req.ScoreToBeat)
+		}
+		resp.HeldDice = m.held.Dice()
 	case yahtzee.FillBox:
-		outcomes := opt.GetFillOutcomes(roll)
-		fill, score := bestBox(outcomes, req.ScoreToBeat)
-		resp.BoxFilled = int(fill)
-		resp.Value = score
-	default:
-		return nil, fmt.Errorf("Invalid turn state: %v", req.TurnState.Step)
+		outcomes := t.fillOutcomes(table, game, roll)
+		m.box, resp.Value = bestBox(outcomes, req.ScoreToBeat)
+		resp.BoxFilled = int(m.box)
+	}
+
+	resp.Values = t.objectiveValues(game, m, req.ScoreToBeat)
+	if req.ScoreToBeat > 0 && resp.Values.ExpectedWork != nil {
+		margin := t.newGameMargin(*resp.Values.ExpectedWork, req.CurrentScore+req.ScoreToBeat)
+		resp.NewGameMargin = &margin
+		resp.NewGame = margin > 0
 	}
 
 	return resp, nil
 }
 
-func (ys *YahtzeeServer) getOutcomes(req *OutcomeDistributionRequest) (*OutcomeDistributionResponse, error) {
+// newGameMargin compares the expected work remaining in the current game
+// to the expected work of starting over (E_0) to achieve the target score.
+func (t tables) newGameMargin(work float32, target int) float32 {
+	if target >= yahtzee.MaxScore {
+		target = yahtzee.MaxScore - 1
+	}
+
+	e0 := t.expectedWork.Compute(yahtzee.NewGame()).(optimization.ExpectedWork)
+	return work - e0.GetValue(target)
+}
+
+// move is a choice made at a particular step of the turn.
+type move struct {
+	step yahtzee.TurnStep
+	roll yahtzee.Roll
+	// held is the dice held, if step is Hold1 or Hold2.
+	held yahtzee.Roll
+	// box is the box filled, if step is FillBox.
+	box yahtzee.Box
+}
+
+// outcome computes the result of making the move with the named table.
+func (t tables) outcome(table string, game yahtzee.GameState, m move) optimization.GameResult {
+	switch m.step {
+	case yahtzee.Hold1, yahtzee.Hold2:
+		return t.holdOutcomes(table, game, m.step, m.roll)[m.held]
+	case yahtzee.FillBox:
+		return t.fillOutcomes(table, game, m.roll)[m.box]
+	}
+
+	return t.get(table).Compute(game)
+}
+
+// objectiveValues evaluates the given move for every objective whose
+// table is loaded. The expected value table is always required.
+func (t tables) objectiveValues(game yahtzee.GameState, m move, scoreToBeat int) ObjectiveValues {
+	ev := t.outcome(TableExpectedValue, game, m)
+	values := ObjectiveValues{ExpectedValue: gameResultValue(ev, scoreToBeat)}
+	if scoreToBeat == 0 {
+		probabilityToBeat, expectedWork := float32(1), float32(0)
+		values.ProbabilityToBeat = &probabilityToBeat
+		values.ExpectedWork = &expectedWork
+		return values
+	}
+
+	if t.scoreDistribution != nil {
+		sd := t.outcome(TableScoreDistribution, game, m)
+		probabilityToBeat := gameResultValue(sd, scoreToBeat)
+		values.ProbabilityToBeat = &probabilityToBeat
+	}
+	if t.expectedWork != nil {
+		ew := t.outcome(TableExpectedWork, game, m)
+		expectedWork := -gameResultValue(ew, scoreToBeat)
+		values.ExpectedWork = &expectedWork
+	}
+
+	return values
+}
+
+// ComputeOutcomeDistribution returns the distribution of final scores
+// for every choice in the position in req. Errors are *APIError.
+func (ys *YahtzeeServer) ComputeOutcomeDistribution(
+	req *OutcomeDistributionRequest) (*OutcomeDistributionResponse, error) {
+	if err := req.validate(); err != nil {
+		return nil, err
+	}
+
+	t, err := ys.getTables(TableExpectedValue, TableScoreDistribution)
+	if err != nil {
+		return nil, err
+	}
+
 	game := req.GameState.ToYahtzeeGameState()
 	roll := asRoll(req.TurnState.Dice)
-	hsOpt := optimization.NewTurnOptimizer(ys.highScoreStrat, game)
-	esOpt := optimization.NewTurnOptimizer(ys.expectedScoreStrat, game)
 	glog.Infof("Computing outcomes for game: %v, roll: %v", game, roll)
 
 	resp := &OutcomeDistributionResponse{}
-	switch req.TurnState.Step {
-	case yahtzee.Hold1:
-		expectedScores := esOpt.GetHold1Outcomes(roll)
-		scoreDistributions := hsOpt.GetHold1Outcomes(roll)
-		resp.HoldChoices = formatHoldChoices(expectedScores, scoreDistributions)
-	case yahtzee.Hold2:
-		expectedScores := esOpt.GetHold2Outcomes(roll)
-		scoreDistributions := hsOpt.GetHold2Outcomes(roll)
-		resp.HoldChoices = formatHoldChoices(expectedScores, scoreDistributions)
-	case yahtzee.FillBox:
-	default:
-		return nil, fmt.Errorf("Invalid turn state: %v", req.TurnState.Step)
+	if req.TurnState.Step == yahtzee.Begin || req.TurnState.HeldDice != nil {
+		m := move{step: req.TurnState.Step, roll: roll, held: asRoll(req.TurnState.HeldDice)}
+		resp.Summary = t.outcomeSummary(req, game, m)
+		return resp, nil
+	}
+
+	step := req.TurnState.Step
+	if step == yahtzee.Hold1 || step == yahtzee.Hold2 {
+		expectedScores := t.holdOutcomes(TableExpectedValue, game, step, roll)
+		scoreDistributions := t.holdOutcomes(TableScoreDistribution, game, step, roll)
+		resp.HoldChoices = formatHoldChoices(req, expectedScores, scoreDistributions)
 	}
 
 	// Always compute the fill outcomes, since a player may choose to fill
 	// a box after only the first or second roll.
-	expectedScores := esOpt.GetFillOutcomes(roll)
-	scoreDistributions := hsOpt.GetFillOutcomes(roll)
-	resp.FillChoices = formatFillChoices(expectedScores, scoreDistributions)
+	expectedScores := t.fillOutcomes(TableExpectedValue, game, roll)
+	scoreDistributions := t.fillOutcomes(TableScoreDistribution, game, roll)
+	resp.FillChoices = formatFillChoices(req, expectedScores, scoreDistributions)
 
 	return resp, nil
 }
 
+// outcomeSummary computes the outcome of making the given move.
+func (t tables) outcomeSummary(req *OutcomeDistributionRequest,
+	game yahtzee.GameState, m move) *OutcomeSummary {
+	ev := t.outcome(TableExpectedValue, game, m).(optimization.ExpectedValue)
+	sd := t.outcome(TableScoreDistribution, game, m).(optimization.ScoreDistribution)
+	return &OutcomeSummary{
+		ExpectedFinalScore:     float32(ev),
+		DistributionSummary:    req.summarize(sd),
+		FinalScoreDistribution: req.distribution(sd),
+	}
+}
+
+// summarize computes the summary statistics of sd requested by req.
+func (req *OutcomeDistributionRequest) summarize(sd optimization.ScoreDistribution) DistributionSummary {
+	qs := req.Quantiles
+	if len(qs) == 0 {
+		qs = DefaultQuantiles
+	}
+
+	summary := DistributionSummary{
+		Mean:      sd.Mean(),
+		Median:    sd.Median(),
+		StdDev:    sd.StdDev(),
+		Quantiles: make([]ScoreQuantile, len(qs)),
+	}
+
+	for i, score := range sd.Quantiles(qs) {
+		summary.Quantiles[i] = ScoreQuantile{qs[i], score}
+	}
+
+	for _, r := range req.Ranges {
+		summary.Ranges = append(summary.Ranges, RangeProbability{
+			Min:         r.Min,
+			Max:         r.Max,
+			Probability: sd.ProbabilityInRange(r.Min, r.Max),
+		})
+	}
+
+	return summary
+}
+
+// distribution returns the FinalScoreDistribution for sd,
+// or nil if a compact response was requested.
+func (req *OutcomeDistributionRequest) distribution(sd optimization.ScoreDistribution) []float32 {
+	if req.Compact {
+		return nil
+	}
+
+	return asDistribution(sd)
+}
+
 func asRoll(dice []int) yahtzee.Roll {
 	r := yahtzee.NewRoll()
 	for _, die := range dice {
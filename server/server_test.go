@@ -0,0 +1,40 @@
+package server
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestOptimalMoveWithoutExpectedWork(t *testing.T) {
+	// The test server does not have the expected work table, so the
+	// probability to beat is reported, but not the expected work.
+	ys := newTestServer()
+	req := &OptimalMoveRequest{}
+	if err := json.Unmarshal([]byte(`{"GameState":`+lateGame+
+		`,"TurnState":{"Step":3,"Dice":[6,6,6,6,6]},"ScoreToBeat":40,"Objective":"probability_to_beat"}`), req); err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := ys.ComputeOptimalMove(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Values.ProbabilityToBeat == nil || *resp.Values.ProbabilityToBeat != resp.Value {
+		t.Errorf("ProbabilityToBeat = %v, expected %v", resp.Values.ProbabilityToBeat, resp.Value)
+	}
+	if resp.Values.ExpectedWork != nil {
+		t.Errorf("ExpectedWork = %v, expected it to be omitted", *resp.Values.ExpectedWork)
+	}
+	if resp.NewGame || resp.NewGameMargin != nil {
+		t.Errorf("NewGame = %v, NewGameMargin = %v, expected them to be unset",
+			resp.NewGame, resp.NewGameMargin)
+	}
+
+	// The expected work objective requires its table.
+	req.Objective = ObjectiveExpectedWork
+	if _, err := ys.ComputeOptimalMove(req); err == nil {
+		t.Error("expected an error for the expected_work objective")
+	} else if code := err.(*APIError).StatusCode(); code != 503 {
+		t.Errorf("status = %d, expected 503", code)
+	}
+}
@@ -0,0 +1,216 @@
+package server
+
+import (
+	"sync"
+	"time"
+
+	"github.com/satori/go.uuid"
+
+	"github.com/timpalpant/yahtzee"
+)
+
+// DefaultSessionTTL is how long an idle game session is kept before
+// it expires.
+const DefaultSessionTTL = 2 * time.Hour
+
+// session tracks a single game on the server, so that clients
+// only need to report the dice they roll and the choices they make.
+type session struct {
+	id          string
+	scoreToBeat int
+	lastAccess  time.Time
+
+	current yahtzee.Turn
+	// history of previous turn states, for undo.
+	history []yahtzee.Turn
+}
+
+func newSession(scoreToBeat int) *session {
+	return &session{
+		id:          uuid.NewV4().String(),
+		scoreToBeat: scoreToBeat,
+		lastAccess:  time.Now(),
+		current:     yahtzee.NewTurn(),
+	}
+}
+
+// move replaces the current turn state with next, unless err is set.
+// Errors from the turn are reported for the given field.
+func (s *session) move(next yahtzee.Turn, err error, field string) error {
+	if err != nil {
+		return moveError(err, field)
+	}
+
+	s.history = append(s.history, s.current)
+	s.current = next
+	return nil
+}
+
+// moveError converts an error from a move of a yahtzee.Turn
+// to an APIError for the given field.
+func moveError(err error, field string) error {
+	moveErr, ok := err.(*yahtzee.MoveError)
+	if !ok {
+		return err
+	}
+
+	switch moveErr.Kind {
+	case yahtzee.InvalidDice:
+		return badRequest(ErrCodeInvalidDice, field, "%v", moveErr)
+	case yahtzee.InvalidBox:
+		return badRequest(ErrCodeInvalidBox, field, "%v", moveErr)
+	case yahtzee.FilledBox:
+		return badRequest(ErrCodeBoxFilled, field, "%v", moveErr)
+	}
+
+	return invalidMove("%v", moveErr)
+}
+
+func (s *session) roll(dice []int) error {
+	if err := validateDice(dice, "Dice"); err != nil {
+		return err
+	}
+
+	next, err := s.current.Roll(dice)
+	return s.move(next, err, "Dice")
+}
+
+func (s *session) hold(held []int) error {
+	if err := validateDiceRange(held, "HeldDice"); err != nil {
+		return err
+	}
+
+	next, err := s.current.Hold(held)
+	return s.move(next, err, "HeldDice")
+}
+
+func (s *session) fill(box int) error {
+	if err := validateBox(box, nil, "Box"); err != nil {
+		return err
+	}
+
+	next, _, err := s.current.Fill(yahtzee.Box(box))
+	return s.move(next, err, "Box")
+}
+
+func (s *session) undo() error {
+	if len(s.history) == 0 {
+		return invalidMove("nothing to undo")
+	}
+
+	s.current = s.history[len(s.history)-1]
+	s.history = s.history[:len(s.history)-1]
+	return nil
+}
+
+func (s *session) toAPI() GameSession {
+	t := s.current
+	return GameSession{
+		ID:          s.id,
+		ScoreToBeat: s.scoreToBeat,
+		GameState:   FromYahtzeeGameState(t.Scorecard.Game),
+		TurnState: TurnState{
+			Step: t.Step(),
+			Dice: t.Dice,
+		},
+		HeldDice:  t.Held,
+		Scorecard: FromYahtzeeScorecard(t.Scorecard),
+		GameOver:  t.Scorecard.Game.GameOver(),
+	}
+}
+
+// sessionStore holds all active game sessions. Sessions expire
+// if they are not accessed within the TTL. While there are sessions,
+// expired sessions are removed every TTL, even if no further sessions
+// are accessed.
+type sessionStore struct {
+	mu       sync.Mutex
+	ttl      time.Duration
+	sessions map[string]*session
+	// expiryTimer is set while the next removal of expired sessions
+	// is scheduled.
+	expiryTimer *time.Timer
+}
+
+func newSessionStore(ttl time.Duration) *sessionStore {
+	return &sessionStore{
+		ttl:      ttl,
+		sessions: make(map[string]*session),
+	}
+}
+
+func (ss *sessionStore) setTTL(ttl time.Duration) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	ss.ttl = ttl
+}
+
+func (ss *sessionStore) create(scoreToBeat int) GameSession {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	ss.expire()
+	s := newSession(scoreToBeat)
+	ss.sessions[s.id] = s
+	ss.scheduleExpiry()
+	return s.toAPI()
+}
+
+func (ss *sessionStore) delete(id string) error {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	if _, ok := ss.sessions[id]; !ok {
+		return notFound("game session %v not found", id)
+	}
+
+	delete(ss.sessions, id)
+	return nil
+}
+
+// update applies fn to the session with the given id, and returns the
+// resulting state of the session. If fn returns an error, the session
+// is left unchanged.
+func (ss *sessionStore) update(id string, fn func(s *session) error) (GameSession, error) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	s, ok := ss.sessions[id]
+	if !ok || time.Since(s.lastAccess) > ss.ttl {
+		delete(ss.sessions, id)
+		return GameSession{}, notFound("game session %v not found", id)
+	}
+
+	s.lastAccess = time.Now()
+	if err := fn(s); err != nil {
+		return GameSession{}, err
+	}
+
+	return s.toAPI(), nil
+}
+
+// expire removes all sessions that have not been accessed within the TTL.
+// The caller must hold ss.mu.
+func (ss *sessionStore) expire() {
+	for id, s := range ss.sessions {
+		if time.Since(s.lastAccess) > ss.ttl {
+			delete(ss.sessions, id)
+		}
+	}
+}
+
+// scheduleExpiry schedules the removal of expired sessions, unless it
+// is already scheduled or there are no sessions. The caller must hold ss.mu.
+func (ss *sessionStore) scheduleExpiry() {
+	if ss.expiryTimer != nil || len(ss.sessions) == 0 {
+		return
+	}
+
+	ss.expiryTimer = time.AfterFunc(ss.ttl, func() {
+		ss.mu.Lock()
+		defer ss.mu.Unlock()
+		ss.expiryTimer = nil
+		ss.expire()
+		ss.scheduleExpiry()
+	})
+}
@@ -0,0 +1,256 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/timpalpant/yahtzee"
+)
+
+// sessionOp is a move to make in a session, and the error code
+// it is expected to fail with ("" if it should succeed).
+type sessionOp struct {
+	op   string
+	dice []int
+	box  yahtzee.Box
+	code string
+}
+
+func (op sessionOp) apply(s *session) error {
+	switch op.op {
+	case "roll":
+		return s.roll(op.dice)
+	case "hold":
+		return s.hold(op.dice)
+	case "fill":
+		return s.fill(int(op.box))
+	case "undo":
+		return s.undo()
+	}
+
+	panic("unknown op: " + op.op)
+}
+
+func errorCode(err error) string {
+	if err == nil {
+		return ""
+	} else if apiErr, ok := err.(*APIError); ok {
+		return apiErr.Code
+	}
+	return err.Error()
+}
+
+func roll(dice ...int) sessionOp     { return sessionOp{op: "roll", dice: dice} }
+func hold(dice ...int) sessionOp     { return sessionOp{op: "hold", dice: dice} }
+func fill(box yahtzee.Box) sessionOp { return sessionOp{op: "fill", box: box} }
+func undo() sessionOp                { return sessionOp{op: "undo"} }
+
+func fails(op sessionOp, code string) sessionOp {
+	op.code = code
+	return op
+}
+
+func TestSessionTurn(t *testing.T) {
+	testCases := []struct {
+		name   string
+		ops    []sessionOp
+		step   yahtzee.TurnStep
+		dice   []int
+		held   []int
+		filled []yahtzee.Box
+	}{
+		{"new game", nil, yahtzee.Begin, nil, nil, nil},
+		{"roll", []sessionOp{roll(1, 2, 3, 4, 5)},
+			yahtzee.Hold1, []int{1, 2, 3, 4, 5}, nil, nil},
+		{"hold", []sessionOp{roll(1, 2, 3, 4, 5), hold(1, 2)},
+			yahtzee.Hold1, []int{1, 2, 3, 4, 5}, []int{1, 2}, nil},
+		{"reroll", []sessionOp{roll(1, 2, 3, 4, 5), hold(1, 2), roll(1, 6, 2, 6, 6)},
+			yahtzee.Hold2, []int{1, 6, 2, 6, 6}, nil, nil},
+		{"hold all skips to fill", []sessionOp{roll(6, 6, 6, 6, 6), hold(6, 6, 6, 6, 6)},
+			yahtzee.FillBox, []int{6, 6, 6, 6, 6}, nil, nil},
+		{"fill", []sessionOp{roll(1, 2, 3, 4, 5), fill(yahtzee.LargeStraight)},
+			yahtzee.Begin, nil, nil, []yahtzee.Box{yahtzee.LargeStraight}},
+		{"hold before roll", []sessionOp{fails(hold(1), ErrCodeInvalidMove)},
+			yahtzee.Begin, nil, nil, nil},
+		{"fill before roll", []sessionOp{fails(fill(yahtzee.Chance), ErrCodeInvalidMove)},
+			yahtzee.Begin, nil, nil, nil},
+		{"fourth roll", []sessionOp{
+			roll(1, 1, 1, 1, 1), roll(2, 2, 2, 2, 2), roll(3, 3, 3, 3, 3),
+			fails(roll(4, 4, 4, 4, 4), ErrCodeInvalidMove),
+		}, yahtzee.FillBox, []int{3, 3, 3, 3, 3}, nil, nil},
+		{"hold after third roll", []sessionOp{
+			roll(1, 1, 1, 1, 1), roll(2, 2, 2, 2, 2), roll(3, 3, 3, 3, 3),
+			fails(hold(3), ErrCodeInvalidMove),
+		}, yahtzee.FillBox, []int{3, 3, 3, 3, 3}, nil, nil},
+		{"roll without held dice", []sessionOp{
+			roll(1, 2, 3, 4, 5), hold(1, 2),
+			fails(roll(1, 3, 3, 3, 3), ErrCodeInvalidDice),
+		}, yahtzee.Hold1, []int{1, 2, 3, 4, 5}, []int{1, 2}, nil},
+		{"hold dice not rolled", []sessionOp{
+			roll(1, 2, 3, 4, 5), fails(hold(1, 1), ErrCodeInvalidDice),
+		}, yahtzee.Hold1, []int{1, 2, 3, 4, 5}, nil, nil},
+		{"invalid roll", []sessionOp{
+			fails(roll(1, 2, 3, 4), ErrCodeInvalidDice),
+			fails(roll(1, 2, 3, 4, 7), ErrCodeInvalidDice),
+		}, yahtzee.Begin, nil, nil, nil},
+		{"fill filled box", []sessionOp{
+			roll(1, 2, 3, 4, 5), fill(yahtzee.Chance),
+			roll(6, 6, 6, 6, 6), fails(fill(yahtzee.Chance), ErrCodeBoxFilled),
+		}, yahtzee.Hold1, []int{6, 6, 6, 6, 6}, nil, []yahtzee.Box{yahtzee.Chance}},
+		{"undo hold", []sessionOp{roll(1, 2, 3, 4, 5), hold(1), undo()},
+			yahtzee.Hold1, []int{1, 2, 3, 4, 5}, nil, nil},
+		{"undo fill", []sessionOp{roll(1, 2, 3, 4, 5), fill(yahtzee.Chance), undo()},
+			yahtzee.Hold1, []int{1, 2, 3, 4, 5}, nil, nil},
+		{"undo everything", []sessionOp{
+			roll(1, 2, 3, 4, 5), hold(1), undo(), undo(),
+			fails(undo(), ErrCodeInvalidMove),
+		}, yahtzee.Begin, nil, nil, nil},
+		{"game over", append(fillAll(),
+			fails(roll(1, 2, 3, 4, 5), ErrCodeInvalidMove)),
+			yahtzee.Begin, nil, nil, allBoxes()},
+	}
+
+	for _, tc := range testCases {
+		s := newSession(0)
+		for i, op := range tc.ops {
+			if code := errorCode(op.apply(s)); code != op.code {
+				t.Errorf("%v: op %d (%v %v): error = %q, expected %q",
+					tc.name, i, op.op, op.dice, code, op.code)
+			}
+		}
+
+		gs := s.toAPI()
+		if gs.TurnState.Step != tc.step {
+			t.Errorf("%v: step = %v, expected %v", tc.name, gs.TurnState.Step, tc.step)
+		}
+		if !reflect.DeepEqual(gs.TurnState.Dice, tc.dice) {
+			t.Errorf("%v: dice = %v, expected %v", tc.name, gs.TurnState.Dice, tc.dice)
+		}
+		if !reflect.DeepEqual(gs.HeldDice, tc.held) {
+			t.Errorf("%v: held = %v, expected %v", tc.name, gs.HeldDice, tc.held)
+		}
+		for box := yahtzee.Ones; box <= yahtzee.Yahtzee; box++ {
+			expected := false
+			for _, filled := range tc.filled {
+				expected = expected || filled == box
+			}
+			if s.current.Scorecard.Game.BoxFilled(box) != expected {
+				t.Errorf("%v: %v filled = %v, expected %v", tc.name, box, !expected, expected)
+			}
+		}
+	}
+}
+
+func allBoxes() []yahtzee.Box {
+	var boxes []yahtzee.Box
+	for box := yahtzee.Ones; box <= yahtzee.Yahtzee; box++ {
+		boxes = append(boxes, box)
+	}
+	return boxes
+}
+
+// fillAll returns the ops to play a complete game.
+func fillAll() []sessionOp {
+	var ops []sessionOp
+	for _, box := range allBoxes() {
+		ops = append(ops, roll(1, 2, 3, 4, 5), fill(box))
+	}
+	return ops
+}
+
+func TestSessionBonuses(t *testing.T) {
+	s := newSession(0)
+	testCases := []struct {
+		dice         []int
+		box          yahtzee.Box
+		upperHalf    int
+		upperBonus   int
+		yahtzeeBonus int
+		total        int
+	}{
+		{[]int{6, 6, 6, 6, 6}, yahtzee.Yahtzee, 0, 0, 0, 50},
+		{[]int{6, 6, 6, 6, 6}, yahtzee.Sixes, 30, 0, 100, 180},
+		{[]int{5, 5, 5, 5, 5}, yahtzee.Fives, 55, 0, 200, 305},
+		{[]int{4, 4, 1, 2, 3}, yahtzee.Fours, 63, 35, 200, 348},
+		// Threes is open, so the joker scores 0 in FullHouse, but
+		// the Yahtzee bonus is still awarded.
+		{[]int{3, 3, 3, 3, 3}, yahtzee.FullHouse, 63, 35, 300, 448},
+	}
+
+	for _, tc := range testCases {
+		if err := s.roll(tc.dice); err != nil {
+			t.Fatal(err)
+		} else if err := s.fill(int(tc.box)); err != nil {
+			t.Fatal(err)
+		}
+
+		sc := s.toAPI().Scorecard
+		if sc.UpperHalfScore != tc.upperHalf || sc.UpperHalfBonus != tc.upperBonus ||
+			sc.YahtzeeBonus != tc.yahtzeeBonus || sc.Total != tc.total {
+			t.Errorf("after filling %v with %v: scorecard = %+v, expected upper half %d, "+
+				"upper half bonus %d, Yahtzee bonus %d, total %d", tc.box, tc.dice, sc,
+				tc.upperHalf, tc.upperBonus, tc.yahtzeeBonus, tc.total)
+		}
+	}
+
+	// Undoing a fill also undoes its bonuses.
+	s.undo()
+	s.undo()
+	s.undo()
+	if sc := s.toAPI().Scorecard; sc.UpperHalfBonus != 0 || sc.YahtzeeBonus != 200 || sc.Total != 305 {
+		t.Errorf("after undo: scorecard = %+v, expected no upper half bonus and total 305", sc)
+	}
+}
+
+func TestSessionsExpireWithoutAccess(t *testing.T) {
+	ss := newSessionStore(10 * time.Millisecond)
+	ss.create(0)
+	ss.create(0)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		ss.mu.Lock()
+		n, timer := len(ss.sessions), ss.expiryTimer
+		ss.mu.Unlock()
+		if n == 0 && timer == nil {
+			break
+		} else if time.Now().After(deadline) {
+			t.Fatalf("%d sessions have not expired", n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestSessionAdviceAfterHold(t *testing.T) {
+	ys := newTestServer()
+	id := newLateGameSession(t, ys)
+	gs, err := ys.sessions.update(id, func(s *session) error { return s.hold([]int{1, 2}) })
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	ys.sessionAdvice(w, id)
+	var resp OptimalMoveResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid advice %q: %v", w.Body, err)
+	}
+
+	// The advice is the value of the dice that were held,
+	// rather than the best dice to hold from the same roll.
+	outcomes, err := ys.ComputeOutcomeDistribution(&OutcomeDistributionRequest{
+		GameState: gs.GameState,
+		TurnState: TurnState{Step: yahtzee.Hold1, Dice: gs.TurnState.Dice, HeldDice: []int{1, 2}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(resp.HeldDice, []int{1, 2}) || resp.Value != outcomes.Summary.ExpectedFinalScore {
+		t.Errorf("advice = %+v, expected to hold [1 2] with value %v",
+			resp, outcomes.Summary.ExpectedFinalScore)
+	}
+}
@@ -0,0 +1,217 @@
+package server
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/golang/glog"
+
+	"github.com/timpalpant/yahtzee"
+	"github.com/timpalpant/yahtzee/optimization"
+)
+
+// Names of the strategy tables used by the server. They match the
+// observables computed by compute_scores.
+const (
+	TableExpectedValue     = "expected_value"
+	TableScoreDistribution = "score_distribution"
+	TableExpectedWork      = "expected_work"
+)
+
+// TableNames are the names of all strategy tables, in the order
+// they are reported by the status endpoints.
+var TableNames = []string{
+	TableExpectedValue,
+	TableScoreDistribution,
+	TableExpectedWork,
+}
+
+// States of a table in TableStatus.
+const (
+	TableUnavailable = "unavailable"
+	TableLoading     = "loading"
+	TableLoaded      = "loaded"
+	TableFailed      = "failed"
+)
+
+// LoadTable loads the named strategy table from filename.
+func LoadTable(name, filename string) (*optimization.Strategy, error) {
+	var observable optimization.GameResult
+	switch name {
+	case TableExpectedValue:
+		observable = optimization.NewExpectedValue()
+	case TableScoreDistribution:
+		observable = optimization.NewScoreDistribution()
+	case TableExpectedWork:
+		observable = optimization.NewExpectedWork(0)
+	default:
+		return nil, fmt.Errorf("unknown table: %v", name)
+	}
+
+	strat := optimization.NewStrategy(observable)
+	if err := strat.LoadCache(filename); err != nil {
+		return nil, err
+	}
+
+	// Without the value of a new game, the table is incomplete and
+	// would be (very slowly) computed on demand.
+	e0, ok := strat.Lookup(yahtzee.NewGame())
+	if !ok {
+		return nil, fmt.Errorf("%v table in %v does not contain a new game", name, filename)
+	}
+
+	if name == TableExpectedWork {
+		// The observable of a game that is over is the expected work
+		// of starting a new game (E_0), which is not known until the
+		// table has been loaded once.
+		glog.Info("Reloading expected work table with initialized E_0")
+		strat = optimization.NewStrategy(e0)
+		if err := strat.LoadCache(filename); err != nil {
+			return nil, err
+		}
+	}
+
+	return strat, nil
+}
+
+// tables is a snapshot of the strategy tables used to serve a request.
+// Tables that are not loaded are nil.
+type tables struct {
+	expectedValue     *optimization.Strategy
+	scoreDistribution *optimization.Strategy
+	expectedWork      *optimization.Strategy
+
+	// generation is incremented whenever a table is set, and
+	// identifies the tables in the keys of the result cache.
+	generation uint64
+	// results caches the turn outcomes computed with the tables.
+	results *resultCache
+}
+
+func (t *tables) get(name string) *optimization.Strategy {
+	switch name {
+	case TableExpectedValue:
+		return t.expectedValue
+	case TableScoreDistribution:
+		return t.scoreDistribution
+	case TableExpectedWork:
+		return t.expectedWork
+	}
+
+	return nil
+}
+
+func (t *tables) set(name string, strat *optimization.Strategy) {
+	switch name {
+	case TableExpectedValue:
+		t.expectedValue = strat
+	case TableScoreDistribution:
+		t.scoreDistribution = strat
+	case TableExpectedWork:
+		t.expectedWork = strat
+	default:
+		panic(fmt.Errorf("unknown table: %v", name))
+	}
+}
+
+// tableStatus tracks the loading of a single table.
+type tableStatus struct {
+	state    string
+	filename string
+	err      error
+	loadTime time.Duration
+}
+
+func (s *tableStatus) toAPI(name string) TableStatus {
+	status := TableStatus{
+		Name:        name,
+		State:       s.state,
+		File:        s.filename,
+		LoadSeconds: s.loadTime.Seconds(),
+	}
+
+	if s.err != nil {
+		status.Error = s.err.Error()
+	}
+
+	return status
+}
+
+// getTables returns a snapshot of the current tables, or a 503 error
+// if any of the required tables are not loaded.
+func (ys *YahtzeeServer) getTables(required ...string) (tables, error) {
+	ys.mu.RLock()
+	defer ys.mu.RUnlock()
+
+	for _, name := range required {
+		if ys.tables.get(name) == nil {
+			return tables{}, unavailable("%v table is not loaded (%v)", name, ys.status[name].state)
+		}
+	}
+
+	return ys.tables, nil
+}
+
+// setTable makes the given table available to serve requests.
+func (ys *YahtzeeServer) setTable(name string, strat *optimization.Strategy, status *tableStatus) {
+	ys.mu.Lock()
+	defer ys.mu.Unlock()
+	ys.tables.set(name, strat)
+	// Outcomes computed with the previous table are no longer valid,
+	// and must not keep it (or its results) in memory.
+	ys.tables.generation++
+	ys.tables.results.clear(ys.tables.generation)
+	ys.status[name] = status
+	recordTable(name, strat, status)
+}
+
+// LoadTableInBackground starts loading the named table from filename.
+// Until it has loaded, requests that require the table fail with
+// status 503 Service Unavailable. If loading fails, the server
+// continues without the table. Reloads wait until loading has finished.
+func (ys *YahtzeeServer) LoadTableInBackground(name, filename string) {
+	ys.reloadMu.RLock()
+	ys.setTable(name, nil, &tableStatus{state: TableLoading, filename: filename})
+	go func() {
+		defer ys.reloadMu.RUnlock()
+		glog.Infof("Loading %v table from %v", name, filename)
+		start := time.Now()
+		strat, err := LoadTable(name, filename)
+		if err != nil {
+			glog.Errorf("Error loading %v table: %v", name, err)
+			ys.setTable(name, nil, &tableStatus{state: TableFailed, filename: filename, err: err})
+			return
+		}
+
+		loadTime := time.Since(start)
+		glog.Infof("Loaded %v table in %v", name, loadTime)
+		ys.setTable(name, strat, &tableStatus{
+			state:    TableLoaded,
+			filename: filename,
+			loadTime: loadTime,
+		})
+	}()
+}
+
+// serverStatus reports the state of all tables.
+func (ys *YahtzeeServer) serverStatus() ServerStatus {
+	ys.mu.RLock()
+	defer ys.mu.RUnlock()
+
+	status := ServerStatus{}
+	nLoaded, nLoading := 0, 0
+	for _, name := range TableNames {
+		ts := ys.status[name].toAPI(name)
+		status.Tables = append(status.Tables, ts)
+		switch ts.State {
+		case TableLoaded:
+			nLoaded++
+		case TableLoading:
+			nLoading++
+		}
+	}
+
+	status.Ready = nLoading == 0 && nLoaded > 0
+	status.Degraded = nLoaded < len(TableNames)
+	return status
+}
@@ -0,0 +1,211 @@
+package server
+
+import (
+	"fmt"
+
+	"github.com/timpalpant/yahtzee"
+)
+
+// validateGameState checks that the GameState could have arisen
+// in a real game.
+func validateGameState(gs GameState, field string) error {
+	if len(gs.Filled) != yahtzee.NumTurns {
+		return badRequest(ErrCodeInvalidGameState, field+".Filled",
+			"expected %d boxes, got %d", yahtzee.NumTurns, len(gs.Filled))
+	}
+
+	// The upper half score can be at most 5 of each filled upper box.
+	maxUHS := 0
+	for box := yahtzee.Ones; box <= yahtzee.Sixes; box++ {
+		if gs.Filled[box] {
+			maxUHS += yahtzee.NDice * int(box+1)
+		}
+	}
+
+	if gs.UpperHalfScore < 0 || gs.UpperHalfScore > maxUHS {
+		return badRequest(ErrCodeInvalidGameState, field+".UpperHalfScore",
+			"upper half score %d is not in range [0, %d] for filled boxes",
+			gs.UpperHalfScore, maxUHS)
+	}
+
+	if gs.YahtzeeBonusEligible && !gs.Filled[yahtzee.Yahtzee] {
+		return badRequest(ErrCodeInvalidGameState, field+".YahtzeeBonusEligible",
+			"cannot be eligible for Yahtzee bonus if Yahtzee is not filled")
+	}
+
+	return nil
+}
+
+// validateTurnState checks that the TurnState has a valid step,
+// that dice are provided for any step after the first roll, and
+// that any held dice are from the current roll.
+func validateTurnState(ts TurnState, field string) error {
+	if ts.Step < yahtzee.Begin || ts.Step > yahtzee.FillBox {
+		return badRequest(ErrCodeInvalidTurnStep, field+".Step",
+			"turn step %d is not in range [%d, %d]", ts.Step, yahtzee.Begin, yahtzee.FillBox)
+	} else if ts.HeldDice != nil && ts.Step != yahtzee.Hold1 && ts.Step != yahtzee.Hold2 {
+		return badRequest(ErrCodeInvalidTurnStep, field+".HeldDice",
+			"dice cannot be held at turn step %d", ts.Step)
+	}
+
+	if ts.Step == yahtzee.Begin {
+		return nil
+	} else if err := validateDice(ts.Dice, field+".Dice"); err != nil {
+		return err
+	} else if ts.HeldDice != nil {
+		return validateHeldDice(ts.HeldDice, ts.Dice, field+".HeldDice")
+	}
+
+	return nil
+}
+
+// validateDice checks that a complete roll of dice is provided.
+func validateDice(dice []int, field string) error {
+	if len(dice) != yahtzee.NDice {
+		return badRequest(ErrCodeInvalidDice, field,
+			"expected %d dice, got %d", yahtzee.NDice, len(dice))
+	}
+
+	return validateDiceRange(dice, field)
+}
+
+// validateHeldDice checks that the held dice are a subset of the roll.
+func validateHeldDice(held, dice []int, field string) error {
+	if len(held) > yahtzee.NDice {
+		return badRequest(ErrCodeInvalidDice, field,
+			"cannot hold more than %d dice, got %d", yahtzee.NDice, len(held))
+	} else if err := validateDiceRange(held, field); err != nil {
+		return err
+	} else if !yahtzee.IsSubset(held, dice) {
+		return badRequest(ErrCodeInvalidDice, field,
+			"held dice %v are not in roll %v", held, dice)
+	}
+
+	return nil
+}
+
+func validateDiceRange(dice []int, field string) error {
+	for i, die := range dice {
+		if die < 1 || die > yahtzee.NSides {
+			return badRequest(ErrCodeInvalidDice, fmt.Sprintf("%s[%d]", field, i),
+				"die %d is not in range [1, %d]", die, yahtzee.NSides)
+		}
+	}
+
+	return nil
+}
+
+// validateBox checks that the box index is valid and, if filled is
+// provided, that the box has not already been filled.
+func validateBox(box int, filled []bool, field string) error {
+	if box < int(yahtzee.Ones) || box > int(yahtzee.Yahtzee) {
+		return badRequest(ErrCodeInvalidBox, field,
+			"box %d is not in range [%d, %d]", box, yahtzee.Ones, yahtzee.Yahtzee)
+	} else if box < len(filled) && filled[box] {
+		return badRequest(ErrCodeBoxFilled, field,
+			"box %v is already filled", yahtzee.Box(box))
+	}
+
+	return nil
+}
+
+func validateScoreToBeat(scoreToBeat int, field string) error {
+	if scoreToBeat < 0 || scoreToBeat >= yahtzee.MaxScore {
+		return badRequest(ErrCodeInvalidScoreToBeat, field,
+			"score to beat %d is not in range [0, %d)", scoreToBeat, yahtzee.MaxScore)
+	}
+
+	return nil
+}
+
+// validatePosition checks the GameState and TurnState of a request,
+// and that there is a move left to make.
+func validatePosition(gs GameState, ts TurnState) error {
+	if err := validateGameState(gs, "GameState"); err != nil {
+		return err
+	} else if err := validateTurnState(ts, "TurnState"); err != nil {
+		return err
+	}
+
+	for _, filled := range gs.Filled {
+		if !filled {
+			return nil
+		}
+	}
+
+	return badRequest(ErrCodeGameOver, "GameState.Filled", "all boxes are filled")
+}
+
+func (req *OptimalMoveRequest) validate() error {
+	if err := validatePosition(req.GameState, req.TurnState); err != nil {
+		return err
+	} else if err := validateScoreToBeat(req.ScoreToBeat, "ScoreToBeat"); err != nil {
+		return err
+	} else if req.CurrentScore < 0 || req.CurrentScore >= yahtzee.MaxScore {
+		return badRequest(ErrCodeInvalidGameState, "CurrentScore",
+			"current score %d is not in range [0, %d)", req.CurrentScore, yahtzee.MaxScore)
+	}
+
+	switch req.objective() {
+	case ObjectiveExpectedValue:
+	case ObjectiveProbabilityToBeat, ObjectiveExpectedWork:
+		if req.ScoreToBeat == 0 {
+			return badRequest(ErrCodeInvalidScoreToBeat, "ScoreToBeat",
+				"score to beat is required for objective %v", req.Objective)
+		}
+	default:
+		return badRequest(ErrCodeInvalidObjective, "Objective",
+			"unknown objective %q", req.Objective)
+	}
+
+	return nil
+}
+
+func (req *OutcomeDistributionRequest) validate() error {
+	if err := validatePosition(req.GameState, req.TurnState); err != nil {
+		return err
+	}
+
+	for i, q := range req.Quantiles {
+		if q < 0 || q > 1 {
+			return badRequest(ErrCodeInvalidQuantile, fmt.Sprintf("Quantiles[%d]", i),
+				"quantile %v is not in range [0, 1]", q)
+		}
+	}
+
+	for i, r := range req.Ranges {
+		if r.Min < 0 || r.Min > r.Max {
+			return badRequest(ErrCodeInvalidRange, fmt.Sprintf("Ranges[%d]", i),
+				"range [%d, %d] is not a valid range of scores", r.Min, r.Max)
+		}
+	}
+
+	return nil
+}
+
+func (req *ExplainRequest) validate() error {
+	if err := validatePosition(req.GameState, req.TurnState); err != nil {
+		return err
+	} else if req.TurnState.Step == yahtzee.Begin {
+		return badRequest(ErrCodeInvalidTurnStep, "TurnState.Step",
+			"there are no choices to explain at step %d", req.TurnState.Step)
+	}
+
+	return validateScoreToBeat(req.ScoreToBeat, "ScoreToBeat")
+}
+
+func (req *LiveAdviceRequest) validate() error {
+	if err := validatePosition(req.GameState, req.TurnState); err != nil {
+		return err
+	}
+
+	return validateScoreToBeat(req.ScoreToBeat, "ScoreToBeat")
+}
+
+func (req *GetScoreRequest) validate() error {
+	if err := validateDice(req.Dice, "Dice"); err != nil {
+		return err
+	}
+
+	return validateBox(req.Box, nil, "Box")
+}
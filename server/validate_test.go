@@ -0,0 +1,124 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/timpalpant/yahtzee"
+)
+
+type validator interface {
+	validate() error
+}
+
+func TestValidate(t *testing.T) {
+	newGame := GameState{Filled: make([]bool, yahtzee.NumTurns)}
+	gameOver := GameState{Filled: make([]bool, yahtzee.NumTurns)}
+	for i := range gameOver.Filled {
+		gameOver.Filled[i] = true
+	}
+	roll := []int{1, 2, 3, 4, 5}
+	rolled := TurnState{Step: yahtzee.Hold1, Dice: roll}
+
+	testCases := []struct {
+		name  string
+		req   validator
+		code  string
+		field string
+	}{
+		{"valid", &OptimalMoveRequest{GameState: newGame, TurnState: rolled}, "", ""},
+		{"valid new turn", &OptimalMoveRequest{GameState: newGame}, "", ""},
+		{"too few dice", &OptimalMoveRequest{GameState: newGame,
+			TurnState: TurnState{Step: yahtzee.Hold1, Dice: []int{1, 2, 3, 4}}},
+			ErrCodeInvalidDice, "TurnState.Dice"},
+		{"too many dice", &OptimalMoveRequest{GameState: newGame,
+			TurnState: TurnState{Step: yahtzee.Hold2, Dice: []int{1, 2, 3, 4, 5, 6}}},
+			ErrCodeInvalidDice, "TurnState.Dice"},
+		{"missing dice", &OptimalMoveRequest{GameState: newGame,
+			TurnState: TurnState{Step: yahtzee.FillBox}},
+			ErrCodeInvalidDice, "TurnState.Dice"},
+		{"die too large", &OptimalMoveRequest{GameState: newGame,
+			TurnState: TurnState{Step: yahtzee.Hold1, Dice: []int{1, 2, 3, 4, 7}}},
+			ErrCodeInvalidDice, "TurnState.Dice[4]"},
+		{"die too small", &OptimalMoveRequest{GameState: newGame,
+			TurnState: TurnState{Step: yahtzee.Hold1, Dice: []int{0, 2, 3, 4, 5}}},
+			ErrCodeInvalidDice, "TurnState.Dice[0]"},
+		{"held dice not rolled", &OptimalMoveRequest{GameState: newGame,
+			TurnState: TurnState{Step: yahtzee.Hold1, Dice: roll, HeldDice: []int{1, 1}}},
+			ErrCodeInvalidDice, "TurnState.HeldDice"},
+		{"too many held dice", &OptimalMoveRequest{GameState: newGame,
+			TurnState: TurnState{Step: yahtzee.Hold1, Dice: roll, HeldDice: []int{1, 2, 3, 4, 5, 6}}},
+			ErrCodeInvalidDice, "TurnState.HeldDice"},
+		{"held die out of range", &OptimalMoveRequest{GameState: newGame,
+			TurnState: TurnState{Step: yahtzee.Hold1, Dice: roll, HeldDice: []int{1, 9}}},
+			ErrCodeInvalidDice, "TurnState.HeldDice[1]"},
+		{"step too large", &OptimalMoveRequest{GameState: newGame,
+			TurnState: TurnState{Step: yahtzee.FillBox + 1, Dice: roll}},
+			ErrCodeInvalidTurnStep, "TurnState.Step"},
+		{"negative step", &OptimalMoveRequest{GameState: newGame,
+			TurnState: TurnState{Step: -1}},
+			ErrCodeInvalidTurnStep, "TurnState.Step"},
+		{"held dice when filling", &OptimalMoveRequest{GameState: newGame,
+			TurnState: TurnState{Step: yahtzee.FillBox, Dice: roll, HeldDice: []int{1}}},
+			ErrCodeInvalidTurnStep, "TurnState.HeldDice"},
+		{"unknown objective", &OptimalMoveRequest{GameState: newGame, TurnState: rolled,
+			Objective: "most_fun"},
+			ErrCodeInvalidObjective, "Objective"},
+		{"objective without score to beat", &OptimalMoveRequest{GameState: newGame, TurnState: rolled,
+			Objective: ObjectiveProbabilityToBeat},
+			ErrCodeInvalidScoreToBeat, "ScoreToBeat"},
+		{"negative score to beat", &OptimalMoveRequest{GameState: newGame, TurnState: rolled,
+			ScoreToBeat: -1},
+			ErrCodeInvalidScoreToBeat, "ScoreToBeat"},
+		{"score to beat too large", &OptimalMoveRequest{GameState: newGame, TurnState: rolled,
+			ScoreToBeat: yahtzee.MaxScore},
+			ErrCodeInvalidScoreToBeat, "ScoreToBeat"},
+		{"negative current score", &OptimalMoveRequest{GameState: newGame, TurnState: rolled,
+			CurrentScore: -1},
+			ErrCodeInvalidGameState, "CurrentScore"},
+		{"too few boxes", &OptimalMoveRequest{GameState: GameState{Filled: make([]bool, 12)}},
+			ErrCodeInvalidGameState, "GameState.Filled"},
+		{"upper half score too large", &OptimalMoveRequest{
+			GameState: GameState{Filled: make([]bool, yahtzee.NumTurns), UpperHalfScore: 1}},
+			ErrCodeInvalidGameState, "GameState.UpperHalfScore"},
+		{"bonus without Yahtzee", &OptimalMoveRequest{
+			GameState: GameState{Filled: make([]bool, yahtzee.NumTurns), YahtzeeBonusEligible: true}},
+			ErrCodeInvalidGameState, "GameState.YahtzeeBonusEligible"},
+		{"game over", &OptimalMoveRequest{GameState: gameOver},
+			ErrCodeGameOver, "GameState.Filled"},
+		{"invalid quantile", &OutcomeDistributionRequest{GameState: newGame, TurnState: rolled,
+			Quantiles: []float32{0.5, 1.5}},
+			ErrCodeInvalidQuantile, "Quantiles[1]"},
+		{"invalid range", &OutcomeDistributionRequest{GameState: newGame, TurnState: rolled,
+			Ranges: []ScoreRange{{Min: 200, Max: 100}}},
+			ErrCodeInvalidRange, "Ranges[0]"},
+		{"explain new turn", &ExplainRequest{GameState: newGame},
+			ErrCodeInvalidTurnStep, "TurnState.Step"},
+		{"explain invalid dice", &ExplainRequest{GameState: newGame,
+			TurnState: TurnState{Step: yahtzee.Hold1, Dice: []int{1, 2, 3}}},
+			ErrCodeInvalidDice, "TurnState.Dice"},
+		{"score too few dice", &GetScoreRequest{Dice: []int{1, 2, 3, 4}, Box: 0},
+			ErrCodeInvalidDice, "Dice"},
+		{"score invalid box", &GetScoreRequest{Dice: roll, Box: int(yahtzee.Yahtzee) + 1},
+			ErrCodeInvalidBox, "Box"},
+	}
+
+	for _, tc := range testCases {
+		err := tc.req.validate()
+		if tc.code == "" {
+			if err != nil {
+				t.Errorf("%v: unexpected error: %v", tc.name, err)
+			}
+			continue
+		}
+
+		apiErr, ok := err.(*APIError)
+		if !ok {
+			t.Errorf("%v: error = %v, expected an APIError", tc.name, err)
+		} else if apiErr.Code != tc.code || apiErr.Field != tc.field {
+			t.Errorf("%v: error code = %q, field = %q, expected %q, %q",
+				tc.name, apiErr.Code, apiErr.Field, tc.code, tc.field)
+		} else if apiErr.status != 400 {
+			t.Errorf("%v: status = %d, expected 400", tc.name, apiErr.status)
+		}
+	}
+}
@@ -0,0 +1,465 @@
+// Package websocket implements the small subset of the WebSocket
+// protocol (RFC 6455) needed to exchange JSON messages with browsers
+// and other clients: the opening handshake (server and client side),
+// unfragmented and fragmented text/binary messages, ping/pong and the
+// closing handshake. Frames that violate the protocol (e.g. unmasked
+// frames from a client, or fragmented control frames) fail the
+// connection with a close frame.
+package websocket
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MaxMessageSize is the largest message that will be accepted from a peer.
+const MaxMessageSize = 1 << 20
+
+// Magic value used to compute Sec-WebSocket-Accept. See RFC 6455, Section 1.3.
+const acceptGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+const (
+	opContinuation = 0x0
+	opText         = 0x1
+	opBinary       = 0x2
+	opClose        = 0x8
+	opPing         = 0x9
+	opPong         = 0xA
+
+	finBit  = 0x80
+	rsvBits = 0x70
+	maskBit = 0x80
+
+	// maxControlPayload is the largest payload of a control frame.
+	maxControlPayload = 125
+
+	// Status codes sent in close frames. See RFC 6455, Section 7.4.1.
+	closeProtocolError   = 1002
+	closeMessageTooLarge = 1009
+)
+
+var ErrMessageTooLarge = errors.New("websocket: message too large")
+
+// Conn is a WebSocket connection. It is safe to call the Write methods
+// concurrently with the Read methods, but only one goroutine may read
+// and one goroutine may write at a time.
+type Conn struct {
+	conn net.Conn
+	br   *bufio.Reader
+
+	// wmu serializes writes, since control frames (pong, close)
+	// may be written by the reader.
+	wmu sync.Mutex
+	bw  *bufio.Writer
+
+	// client is true for the client side of the connection,
+	// which must mask the frames it sends.
+	client bool
+}
+
+// Upgrade performs the server side of the opening handshake, and
+// takes over the underlying connection of the request.
+// If the handshake fails, an HTTP error is written to w.
+//
+// Handshakes from a web page on another origin are rejected, so that
+// other sites cannot open connections from a user's browser, unless
+// the origin is one of allowedOrigins (e.g. "https://example.com").
+func Upgrade(w http.ResponseWriter, r *http.Request, allowedOrigins []string) (*Conn, error) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return nil, fmt.Errorf("websocket: invalid method %v", r.Method)
+	} else if !headerContains(r.Header, "Connection", "upgrade") ||
+		!headerContains(r.Header, "Upgrade", "websocket") {
+		w.WriteHeader(http.StatusBadRequest)
+		return nil, errors.New("websocket: not a websocket upgrade request")
+	} else if r.Header.Get("Sec-WebSocket-Version") != "13" {
+		w.Header().Set("Sec-WebSocket-Version", "13")
+		w.WriteHeader(http.StatusUpgradeRequired)
+		return nil, errors.New("websocket: unsupported version")
+	} else if origin := r.Header.Get("Origin"); !checkOrigin(origin, r.Host, allowedOrigins) {
+		w.WriteHeader(http.StatusForbidden)
+		return nil, fmt.Errorf("websocket: origin %v not allowed", origin)
+	}
+
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if key == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		return nil, errors.New("websocket: missing Sec-WebSocket-Key")
+	}
+
+	hj, ok := w.(http.Hijacker)
+	if !ok {
+		w.WriteHeader(http.StatusInternalServerError)
+		return nil, errors.New("websocket: response does not support hijacking")
+	}
+
+	conn, rw, err := hj.Hijack()
+	if err != nil {
+		return nil, err
+	}
+
+	rw.WriteString("HTTP/1.1 101 Switching Protocols\r\n")
+	rw.WriteString("Upgrade: websocket\r\n")
+	rw.WriteString("Connection: Upgrade\r\n")
+	rw.WriteString("Sec-WebSocket-Accept: " + acceptKey(key) + "\r\n\r\n")
+	if err := rw.Flush(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return &Conn{conn: conn, br: rw.Reader, bw: rw.Writer}, nil
+}
+
+// checkOrigin returns true if a handshake with the given Origin header
+// may connect to host. Requests without an Origin are not from a
+// browser, and are always allowed.
+func checkOrigin(origin, host string, allowedOrigins []string) bool {
+	if origin == "" {
+		return true
+	}
+
+	for _, allowed := range allowedOrigins {
+		if strings.EqualFold(origin, allowed) {
+			return true
+		}
+	}
+
+	u, err := url.Parse(origin)
+	return err == nil && strings.EqualFold(u.Host, host)
+}
+
+// Dial performs the client side of the opening handshake with the
+// server at the given URL, which may use the ws, wss, http or https scheme.
+// The context only applies to the handshake.
+func Dial(ctx context.Context, rawurl string) (*Conn, error) {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return nil, err
+	}
+
+	secure := false
+	switch u.Scheme {
+	case "ws", "http":
+		u.Scheme = "http"
+	case "wss", "https":
+		u.Scheme = "https"
+		secure = true
+	default:
+		return nil, fmt.Errorf("websocket: unsupported scheme %v", u.Scheme)
+	}
+
+	host := u.Host
+	if u.Port() == "" {
+		port := "80"
+		if secure {
+			port = "443"
+		}
+		host = net.JoinHostPort(u.Hostname(), port)
+	}
+
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", host)
+	if err != nil {
+		return nil, err
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	c, err := handshake(conn, u, secure)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	conn.SetDeadline(time.Time{})
+	return c, nil
+}
+
+func handshake(conn net.Conn, u *url.URL, secure bool) (*Conn, error) {
+	if secure {
+		tlsConn := tls.Client(conn, &tls.Config{ServerName: u.Hostname()})
+		if err := tlsConn.Handshake(); err != nil {
+			return nil, err
+		}
+		conn = tlsConn
+	}
+
+	var nonce [16]byte
+	if _, err := rand.Read(nonce[:]); err != nil {
+		return nil, err
+	}
+	key := base64.StdEncoding.EncodeToString(nonce[:])
+
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	req.Header.Set("Sec-WebSocket-Key", key)
+	if err := req.Write(conn); err != nil {
+		return nil, err
+	}
+
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, req)
+	if err != nil {
+		return nil, err
+	} else if resp.StatusCode != http.StatusSwitchingProtocols {
+		return nil, fmt.Errorf("websocket: handshake failed with status %v", resp.Status)
+	} else if resp.Header.Get("Sec-WebSocket-Accept") != acceptKey(key) {
+		return nil, errors.New("websocket: invalid Sec-WebSocket-Accept")
+	}
+
+	return &Conn{conn: conn, br: br, bw: bufio.NewWriter(conn), client: true}, nil
+}
+
+// ReadMessage returns the next text or binary message from the peer.
+// Control frames are handled transparently. When the peer closes
+// the connection, ReadMessage returns io.EOF.
+func (c *Conn) ReadMessage() ([]byte, error) {
+	var message []byte
+	fragmented := false
+	for {
+		fin, opcode, payload, err := c.readFrame()
+		if err == ErrMessageTooLarge {
+			return nil, c.fail(closeMessageTooLarge, err)
+		} else if err != nil {
+			return nil, err
+		}
+
+		switch opcode {
+		case opPing:
+			if err := c.writeFrame(opPong, payload); err != nil {
+				return nil, err
+			}
+			continue
+		case opPong:
+			continue
+		case opClose:
+			c.writeFrame(opClose, payload)
+			return nil, io.EOF
+		case opText, opBinary:
+			if fragmented {
+				return nil, c.fail(closeProtocolError,
+					errors.New("websocket: new message before the end of a fragmented message"))
+			}
+		case opContinuation:
+			if !fragmented {
+				return nil, c.fail(closeProtocolError,
+					errors.New("websocket: continuation frame without a message"))
+			}
+		default:
+			return nil, c.fail(closeProtocolError,
+				fmt.Errorf("websocket: unknown opcode %v", opcode))
+		}
+
+		if len(message)+len(payload) > MaxMessageSize {
+			return nil, c.fail(closeMessageTooLarge, ErrMessageTooLarge)
+		}
+		message = append(message, payload...)
+		if fin {
+			return message, nil
+		}
+		fragmented = true
+	}
+}
+
+// WriteMessage sends the given data to the peer as a text message.
+func (c *Conn) WriteMessage(data []byte) error {
+	return c.writeFrame(opText, data)
+}
+
+// ReadJSON reads the next message and decodes it into v.
+func (c *Conn) ReadJSON(v interface{}) error {
+	data, err := c.ReadMessage()
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, v)
+}
+
+// WriteJSON encodes v and sends it to the peer as a text message.
+func (c *Conn) WriteJSON(v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	return c.WriteMessage(data)
+}
+
+// Close sends a close frame to the peer and closes the connection.
+func (c *Conn) Close() error {
+	c.writeFrame(opClose, nil)
+	return c.conn.Close()
+}
+
+// fail sends a close frame with the given status code to the peer,
+// and returns err. See RFC 6455, Section 7.1.7.
+func (c *Conn) fail(code uint16, err error) error {
+	var payload [2]byte
+	binary.BigEndian.PutUint16(payload[:], code)
+	c.writeFrame(opClose, payload[:])
+	return err
+}
+
+// readFrame reads the next frame, and checks that its header is valid.
+func (c *Conn) readFrame() (fin bool, opcode byte, payload []byte, err error) {
+	var header [2]byte
+	if _, err = io.ReadFull(c.br, header[:]); err != nil {
+		return
+	}
+
+	fin = header[0]&finBit != 0
+	opcode = header[0] & 0x0f
+	masked := header[1]&maskBit != 0
+	length := uint64(header[1] & 0x7f)
+	if err = c.checkHeader(header[0], fin, opcode, masked, length); err != nil {
+		return
+	}
+	switch length {
+	case 126:
+		var ext [2]byte
+		if _, err = io.ReadFull(c.br, ext[:]); err != nil {
+			return
+		}
+		length = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err = io.ReadFull(c.br, ext[:]); err != nil {
+			return
+		}
+		length = binary.BigEndian.Uint64(ext[:])
+	}
+
+	if length > MaxMessageSize {
+		err = ErrMessageTooLarge
+		return
+	}
+
+	var mask [4]byte
+	if masked {
+		if _, err = io.ReadFull(c.br, mask[:]); err != nil {
+			return
+		}
+	}
+
+	payload = make([]byte, length)
+	if _, err = io.ReadFull(c.br, payload); err != nil {
+		return
+	}
+
+	if masked {
+		for i := range payload {
+			payload[i] ^= mask[i%4]
+		}
+	}
+
+	return
+}
+
+// checkHeader fails the connection if the header of a frame is invalid.
+// Clients must mask every frame they send and servers must not (RFC 6455,
+// Section 5.1), and control frames must not be fragmented or have a
+// payload longer than 125 bytes (Section 5.5).
+func (c *Conn) checkHeader(b0 byte, fin bool, opcode byte, masked bool, length uint64) error {
+	var err error
+	switch {
+	case b0&rsvBits != 0:
+		err = errors.New("websocket: reserved bits set without an extension")
+	case masked && c.client:
+		err = errors.New("websocket: masked frame from server")
+	case !masked && !c.client:
+		err = errors.New("websocket: unmasked frame from client")
+	case opcode&0x8 != 0 && !fin:
+		err = errors.New("websocket: fragmented control frame")
+	case opcode&0x8 != 0 && length > maxControlPayload:
+		err = errors.New("websocket: control frame too large")
+	default:
+		return nil
+	}
+
+	return c.fail(closeProtocolError, err)
+}
+
+func (c *Conn) writeFrame(opcode byte, payload []byte) error {
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+
+	header := []byte{finBit | opcode}
+	length := len(payload)
+	switch {
+	case length < 126:
+		header = append(header, byte(length))
+	case length <= 0xffff:
+		header = append(header, 126, 0, 0)
+		binary.BigEndian.PutUint16(header[2:], uint16(length))
+	default:
+		header = append(header, 127, 0, 0, 0, 0, 0, 0, 0, 0)
+		binary.BigEndian.PutUint64(header[2:], uint64(length))
+	}
+
+	if c.client {
+		var mask [4]byte
+		if _, err := rand.Read(mask[:]); err != nil {
+			return err
+		}
+
+		header[1] |= maskBit
+		header = append(header, mask[:]...)
+		masked := make([]byte, length)
+		for i := range payload {
+			masked[i] = payload[i] ^ mask[i%4]
+		}
+		payload = masked
+	}
+
+	if _, err := c.bw.Write(header); err != nil {
+		return err
+	}
+	if _, err := c.bw.Write(payload); err != nil {
+		return err
+	}
+
+	return c.bw.Flush()
+}
+
+func acceptKey(key string) string {
+	h := sha1.New()
+	io.WriteString(h, key+acceptGUID)
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
+
+// headerContains checks whether the comma-separated header contains
+// the given token (case-insensitive).
+func headerContains(header http.Header, name, token string) bool {
+	for _, value := range header[name] {
+		for _, t := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(t), token) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
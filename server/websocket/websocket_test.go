@@ -0,0 +1,338 @@
+package websocket
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newConn(conn net.Conn, client bool) *Conn {
+	return &Conn{conn: conn, br: bufio.NewReader(conn), bw: bufio.NewWriter(conn), client: client}
+}
+
+// pipe returns the server and client sides of a connection.
+func pipe() (server, client *Conn) {
+	a, b := net.Pipe()
+	return newConn(a, false), newConn(b, true)
+}
+
+// frame returns the bytes of a frame with a small payload.
+func frame(fin bool, opcode byte, payload string, masked bool) []byte {
+	b0 := opcode
+	if fin {
+		b0 |= finBit
+	}
+
+	result := []byte{b0, byte(len(payload))}
+	if len(payload) >= 126 {
+		result = []byte{b0, 126, 0, 0}
+		binary.BigEndian.PutUint16(result[2:], uint16(len(payload)))
+	}
+
+	data := []byte(payload)
+	if masked {
+		mask := []byte{1, 2, 3, 4}
+		result[1] |= maskBit
+		result = append(result, mask...)
+		for i := range data {
+			data[i] ^= mask[i%4]
+		}
+	}
+
+	return append(result, data...)
+}
+
+func closePayload(code uint16) string {
+	var payload [2]byte
+	binary.BigEndian.PutUint16(payload[:], code)
+	return string(payload[:])
+}
+
+func TestUpgradeRejectsInvalidHandshake(t *testing.T) {
+	testCases := []struct {
+		name     string
+		method   string
+		header   map[string]string
+		expected int
+	}{
+		{"method", "POST", map[string]string{
+			"Connection": "Upgrade", "Upgrade": "websocket",
+			"Sec-WebSocket-Version": "13", "Sec-WebSocket-Key": "a2V5"}, 405},
+		{"not an upgrade", "GET", map[string]string{
+			"Sec-WebSocket-Version": "13", "Sec-WebSocket-Key": "a2V5"}, 400},
+		{"version", "GET", map[string]string{
+			"Connection": "keep-alive, Upgrade", "Upgrade": "websocket",
+			"Sec-WebSocket-Version": "8", "Sec-WebSocket-Key": "a2V5"}, 426},
+		{"missing key", "GET", map[string]string{
+			"Connection": "Upgrade", "Upgrade": "websocket",
+			"Sec-WebSocket-Version": "13"}, 400},
+		{"cross origin", "GET", map[string]string{
+			"Connection": "Upgrade", "Upgrade": "websocket",
+			"Sec-WebSocket-Version": "13", "Sec-WebSocket-Key": "a2V5",
+			"Origin": "https://evil.example.com"}, 403},
+	}
+
+	for _, tc := range testCases {
+		r := httptest.NewRequest(tc.method, "/live", nil)
+		for key, value := range tc.header {
+			r.Header.Set(key, value)
+		}
+		w := httptest.NewRecorder()
+		if _, err := Upgrade(w, r, nil); err == nil {
+			t.Errorf("%v: expected handshake to fail", tc.name)
+		} else if w.Code != tc.expected {
+			t.Errorf("%v: status = %d, expected %d", tc.name, w.Code, tc.expected)
+		}
+	}
+}
+
+func TestCheckOrigin(t *testing.T) {
+	allowed := []string{"https://example.com"}
+	testCases := []struct {
+		origin   string
+		expected bool
+	}{
+		{"", true},
+		{"http://localhost:8080", true},
+		{"http://LOCALHOST:8080", true},
+		{"https://example.com", true},
+		{"http://localhost:8081", false},
+		{"https://evil.example.com", false},
+		{"null", false},
+	}
+
+	for _, tc := range testCases {
+		if result := checkOrigin(tc.origin, "localhost:8080", allowed); result != tc.expected {
+			t.Errorf("checkOrigin(%q) = %v, expected %v", tc.origin, result, tc.expected)
+		}
+	}
+}
+
+func TestAcceptKey(t *testing.T) {
+	// Example from RFC 6455, Section 1.3.
+	if key := acceptKey("dGhlIHNhbXBsZSBub25jZQ=="); key != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Errorf("acceptKey() = %v, expected s3pPLMBiTxaQ9kYGzzhZRbK+xOo=", key)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	closed := make(chan error, 1)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := Upgrade(w, r, nil)
+		if err != nil {
+			closed <- err
+			return
+		}
+
+		for {
+			msg, err := conn.ReadMessage()
+			if err != nil {
+				conn.conn.Close()
+				closed <- err
+				return
+			}
+			conn.WriteMessage(msg)
+		}
+	}))
+	defer ts.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	conn, err := Dial(ctx, "ws"+strings.TrimPrefix(ts.URL, "http"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Messages that use each of the payload length encodings.
+	for _, size := range []int{0, 5, 200, 70000} {
+		msg := bytes.Repeat([]byte("x"), size)
+		if err := conn.WriteMessage(msg); err != nil {
+			t.Fatal(err)
+		}
+		echo, err := conn.ReadMessage()
+		if err != nil {
+			t.Fatal(err)
+		} else if !bytes.Equal(echo, msg) {
+			t.Errorf("echo of %d bytes has %d bytes", size, len(echo))
+		}
+	}
+
+	type message struct{ Greeting string }
+	if err := conn.WriteJSON(message{"hello"}); err != nil {
+		t.Fatal(err)
+	}
+	var echo message
+	if err := conn.ReadJSON(&echo); err != nil {
+		t.Fatal(err)
+	} else if echo.Greeting != "hello" {
+		t.Errorf("echo = %+v, expected hello", echo)
+	}
+
+	if err := conn.writeFrame(opPing, []byte("ping")); err != nil {
+		t.Fatal(err)
+	}
+	if _, opcode, payload, err := conn.readFrame(); err != nil {
+		t.Fatal(err)
+	} else if opcode != opPong || string(payload) != "ping" {
+		t.Errorf("response to ping = %v %q, expected pong", opcode, payload)
+	}
+
+	conn.Close()
+	if err := <-closed; err != io.EOF {
+		t.Errorf("server read error = %v, expected io.EOF", err)
+	}
+}
+
+func TestMasking(t *testing.T) {
+	server, client := pipe()
+	defer server.conn.Close()
+	defer client.conn.Close()
+
+	// Frames from the client are masked.
+	go client.WriteMessage([]byte("hello"))
+	raw := make([]byte, 2+4+5)
+	if _, err := io.ReadFull(server.conn, raw); err != nil {
+		t.Fatal(err)
+	}
+	if raw[1] != maskBit|5 {
+		t.Errorf("client frame length byte = %#x, expected mask bit and length 5", raw[1])
+	}
+	for i := range raw[6:] {
+		raw[6+i] ^= raw[2+i%4]
+	}
+	if string(raw[6:]) != "hello" {
+		t.Errorf("unmasked payload = %q, expected hello", raw[6:])
+	}
+
+	// Frames from the server are not.
+	go server.WriteMessage([]byte("hello"))
+	raw = make([]byte, 2+5)
+	if _, err := io.ReadFull(client.conn, raw); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(raw, frame(true, opText, "hello", false)) {
+		t.Errorf("server frame = %v, expected unmasked text frame", raw)
+	}
+}
+
+// response is a frame sent by the side of the connection under test.
+type response struct {
+	opcode  byte
+	payload string
+}
+
+func TestReadMessage(t *testing.T) {
+	protocolError := &response{opClose, closePayload(closeProtocolError)}
+	testCases := []struct {
+		name string
+		// client is true to test the client side of the connection.
+		client   bool
+		frames   [][]byte
+		response *response
+		expected string
+		err      bool
+	}{
+		{"masked", false, [][]byte{frame(true, opText, "hello", true)}, nil, "hello", false},
+		{"unmasked from client", false, [][]byte{frame(true, opText, "hello", false)},
+			protocolError, "", true},
+		{"unmasked from server", true, [][]byte{frame(true, opText, "hello", false)}, nil, "hello", false},
+		{"masked from server", true, [][]byte{frame(true, opText, "hello", true)},
+			protocolError, "", true},
+		{"reserved bits", false, [][]byte{append([]byte{finBit | 0x40 | opText}, frame(true, opText, "x", true)[1:]...)},
+			protocolError, "", true},
+		{"fragmented", false, [][]byte{
+			frame(false, opText, "hel", true),
+			frame(false, opContinuation, "l", true),
+			frame(true, opContinuation, "o", true),
+		}, nil, "hello", false},
+		{"ping between fragments", false, [][]byte{
+			frame(false, opBinary, "hel", true),
+			frame(true, opPing, "p", true),
+			frame(true, opContinuation, "lo", true),
+		}, &response{opPong, "p"}, "hello", false},
+		{"pong", false, [][]byte{
+			frame(true, opPong, "p", true),
+			frame(true, opText, "hello", true),
+		}, nil, "hello", false},
+		{"continuation without message", false, [][]byte{frame(true, opContinuation, "x", true)},
+			protocolError, "", true},
+		{"message before end of fragmented message", false, [][]byte{
+			frame(false, opText, "a", true),
+			frame(true, opText, "b", true),
+		}, protocolError, "", true},
+		{"fragmented ping", false, [][]byte{frame(false, opPing, "p", true)},
+			protocolError, "", true},
+		{"fragmented close", false, [][]byte{frame(false, opClose, "", true)},
+			protocolError, "", true},
+		{"ping too large", false, [][]byte{frame(true, opPing, strings.Repeat("p", 126), true)},
+			protocolError, "", true},
+		{"unknown opcode", false, [][]byte{frame(true, 0x3, "x", true)},
+			protocolError, "", true},
+		{"close", false, [][]byte{frame(true, opClose, closePayload(1000), true)},
+			&response{opClose, closePayload(1000)}, "", true},
+	}
+
+	for _, tc := range testCases {
+		conn, peer := pipe()
+		if tc.client {
+			conn, peer = peer, conn
+		}
+
+		go func(frames [][]byte) {
+			for _, f := range frames {
+				if _, err := peer.conn.Write(f); err != nil {
+					return
+				}
+			}
+		}(tc.frames)
+
+		type result struct {
+			msg []byte
+			err error
+		}
+		done := make(chan result, 1)
+		go func() {
+			msg, err := conn.ReadMessage()
+			done <- result{msg, err}
+		}()
+
+		if tc.response != nil {
+			if _, opcode, payload, err := peer.readFrame(); err != nil {
+				t.Errorf("%v: error reading response: %v", tc.name, err)
+			} else if opcode != tc.response.opcode || string(payload) != tc.response.payload {
+				t.Errorf("%v: response = %v %q, expected %v %q", tc.name,
+					opcode, payload, tc.response.opcode, tc.response.payload)
+			}
+		}
+
+		r := <-done
+		if tc.err && r.err == nil {
+			t.Errorf("%v: expected error, got message %q", tc.name, r.msg)
+		} else if !tc.err && r.err != nil {
+			t.Errorf("%v: unexpected error: %v", tc.name, r.err)
+		} else if string(r.msg) != tc.expected {
+			t.Errorf("%v: message = %q, expected %q", tc.name, r.msg, tc.expected)
+		}
+
+		conn.conn.Close()
+		peer.conn.Close()
+	}
+}
+
+func TestClose(t *testing.T) {
+	server, client := pipe()
+	defer server.conn.Close()
+
+	go client.Close()
+	if _, err := server.ReadMessage(); err != io.EOF {
+		t.Errorf("ReadMessage() error = %v, expected io.EOF", err)
+	}
+}
@@ -0,0 +1,147 @@
+package yahtzee
+
+import (
+	"fmt"
+)
+
+// Turn tracks a game through each move of a turn: the dice that are
+// rolled, the dice that are held, and the box that is filled.
+//
+// Like Scorecard, a Turn is a value type: each move returns a new Turn,
+// so previous Turns may be retained cheaply (e.g. for undo). The dice
+// slices are never modified once they are in a Turn.
+type Turn struct {
+	Scorecard Scorecard
+	// Rolls is the number of times the dice have been rolled this turn.
+	Rolls int
+	// Dice are the dice of the last roll, or nil before the first roll.
+	Dice []int
+	// Held are the dice from the last roll that are kept for the next
+	// roll, or nil if the dice have not been held since they were rolled.
+	Held []int
+}
+
+// NewTurn returns the first Turn of a new game.
+func NewTurn() Turn {
+	return Turn{Scorecard: NewScorecard()}
+}
+
+// Step returns the TurnStep of the current dice.
+func (t Turn) Step() TurnStep {
+	return TurnStep(t.Rolls)
+}
+
+// NeedsRoll returns true if the next move is expected to be a roll:
+// at the beginning of the turn, or once dice have been held.
+func (t Turn) NeedsRoll() bool {
+	return t.Dice == nil || t.Held != nil
+}
+
+// Roll records the dice that were rolled, which must include any
+// held dice. The dice may be rolled up to three times in a turn.
+func (t Turn) Roll(dice []int) (Turn, error) {
+	if t.Scorecard.Game.GameOver() {
+		return t, moveErrorf(IllegalMove, "the game is over")
+	} else if t.Step() >= FillBox {
+		return t, moveErrorf(IllegalMove, "no rolls remaining, a box must be filled")
+	} else if len(dice) != NDice {
+		return t, moveErrorf(InvalidDice, "expected %d dice, got %d", NDice, len(dice))
+	}
+
+	for _, die := range dice {
+		if die < 1 || die > NSides {
+			return t, moveErrorf(InvalidDice, "die %d is not in range [1, %d]", die, NSides)
+		}
+	}
+
+	if !IsSubset(t.Held, dice) {
+		return t, moveErrorf(InvalidDice, "roll %v does not contain held dice %v", dice, t.Held)
+	}
+
+	t.Rolls++
+	t.Dice = append([]int(nil), dice...)
+	t.Held = nil
+	return t, nil
+}
+
+// Hold keeps the given dice from the last roll, and rerolls the others.
+// Holding all of the dice skips the remaining rolls.
+func (t Turn) Hold(held []int) (Turn, error) {
+	if t.Rolls == 0 {
+		return t, moveErrorf(IllegalMove, "the dice must be rolled before holding dice")
+	} else if t.Step() >= FillBox {
+		return t, moveErrorf(IllegalMove, "no rolls remaining, a box must be filled")
+	} else if !IsSubset(held, t.Dice) {
+		return t, moveErrorf(InvalidDice, "cannot hold %v from roll %v", held, t.Dice)
+	}
+
+	if len(held) == NDice {
+		t.Rolls = int(FillBox)
+		t.Held = nil
+		return t, nil
+	}
+
+	t.Held = append([]int{}, held...)
+	return t, nil
+}
+
+// Fill plays the dice of the last roll in box, and begins the next
+// turn. It returns the number of points scored, including bonuses.
+func (t Turn) Fill(box Box) (Turn, int, error) {
+	if t.Rolls == 0 {
+		return t, 0, moveErrorf(IllegalMove, "the dice must be rolled before filling a box")
+	} else if box < Ones || box > Yahtzee {
+		return t, 0, moveErrorf(InvalidBox, "box %d is not in range [%d, %d]", box, Ones, Yahtzee)
+	} else if t.Scorecard.Game.BoxFilled(box) {
+		return t, 0, moveErrorf(FilledBox, "%v has already been filled", box)
+	}
+
+	scorecard, value := t.Scorecard.Fill(box, NewRollFromDice(t.Dice))
+	return Turn{Scorecard: scorecard}, value, nil
+}
+
+// IsSubset returns true if every die in sub is also in dice,
+// accounting for multiplicity.
+func IsSubset(sub, dice []int) bool {
+	counts := make(map[int]int, len(dice))
+	for _, die := range dice {
+		counts[die]++
+	}
+
+	for _, die := range sub {
+		if counts[die] <= 0 {
+			return false
+		}
+		counts[die]--
+	}
+
+	return true
+}
+
+// MoveErrorKind is the reason that a move is not allowed.
+type MoveErrorKind int
+
+const (
+	// IllegalMove is a move that cannot be made at this point in the turn.
+	IllegalMove MoveErrorKind = iota
+	// InvalidDice are dice that cannot be rolled or held.
+	InvalidDice
+	// InvalidBox is a box that does not exist.
+	InvalidBox
+	// FilledBox is a box that has already been filled.
+	FilledBox
+)
+
+// MoveError is returned by the moves of a Turn that are not allowed.
+type MoveError struct {
+	Kind    MoveErrorKind
+	Message string
+}
+
+func moveErrorf(kind MoveErrorKind, format string, args ...interface{}) *MoveError {
+	return &MoveError{Kind: kind, Message: fmt.Sprintf(format, args...)}
+}
+
+func (e *MoveError) Error() string {
+	return e.Message
+}
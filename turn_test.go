@@ -0,0 +1,118 @@
+package yahtzee
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTurn(t *testing.T) {
+	turn, err := NewTurn().Roll([]int{6, 2, 6, 3, 1})
+	if err != nil {
+		t.Fatal(err)
+	} else if turn.Step() != Hold1 || turn.NeedsRoll() {
+		t.Errorf("Roll() = %+v, expected to choose dice to hold at Hold1", turn)
+	}
+
+	turn, err = turn.Hold([]int{6, 6})
+	if err != nil {
+		t.Fatal(err)
+	} else if !turn.NeedsRoll() || !reflect.DeepEqual(turn.Dice, []int{6, 2, 6, 3, 1}) {
+		t.Errorf("Hold() = %+v, expected to keep the dice until they are rerolled", turn)
+	}
+
+	// Holding no dice rerolls all of them.
+	if next, err := turn.Hold(nil); err != nil {
+		t.Error(err)
+	} else if !next.NeedsRoll() || len(next.Held) != 0 {
+		t.Errorf("Hold(nil) = %+v, expected to reroll all of the dice", next)
+	}
+
+	turn, err = turn.Roll([]int{6, 6, 4, 4, 4})
+	if err != nil {
+		t.Fatal(err)
+	} else if turn.Step() != Hold2 || turn.Held != nil {
+		t.Errorf("Roll() = %+v, expected Hold2", turn)
+	}
+
+	// Holding all of the dice skips to filling a box.
+	turn, err = turn.Hold(turn.Dice)
+	if err != nil {
+		t.Fatal(err)
+	} else if turn.Step() != FillBox || turn.NeedsRoll() {
+		t.Errorf("Hold() = %+v, expected to fill a box", turn)
+	}
+
+	turn, value, err := turn.Fill(FullHouse)
+	if err != nil {
+		t.Fatal(err)
+	} else if value != 25 || turn.Rolls != 0 || turn.Dice != nil || !turn.Scorecard.Game.BoxFilled(FullHouse) {
+		t.Errorf("Fill() = %+v, %v, expected 25 in FullHouse and a new turn", turn, value)
+	}
+}
+
+func TestTurnErrors(t *testing.T) {
+	rolled, _ := NewTurn().Roll([]int{1, 2, 3, 4, 5})
+	held, _ := rolled.Hold([]int{1, 2})
+	third := rolled
+	for i := 0; i < 2; i++ {
+		third, _ = third.Roll([]int{1, 2, 3, 4, 5})
+	}
+	filled, _, _ := rolled.Fill(Chance)
+	filled, _ = filled.Roll([]int{1, 2, 3, 4, 5})
+	gameOver := NewTurn()
+	for box := Ones; box <= Yahtzee; box++ {
+		gameOver, _ = gameOver.Roll([]int{1, 2, 3, 4, 5})
+		gameOver, _, _ = gameOver.Fill(box)
+	}
+
+	testCases := []struct {
+		name string
+		move func() error
+		kind MoveErrorKind
+	}{
+		{"hold before roll", func() error { _, err := NewTurn().Hold(nil); return err }, IllegalMove},
+		{"fill before roll", func() error { _, _, err := NewTurn().Fill(Chance); return err }, IllegalMove},
+		{"fourth roll", func() error { _, err := third.Roll([]int{1, 2, 3, 4, 5}); return err }, IllegalMove},
+		{"hold after third roll", func() error { _, err := third.Hold([]int{1}); return err }, IllegalMove},
+		{"roll after game over", func() error { _, err := gameOver.Roll([]int{1, 2, 3, 4, 5}); return err }, IllegalMove},
+		{"too few dice", func() error { _, err := rolled.Roll([]int{1, 2, 3, 4}); return err }, InvalidDice},
+		{"die out of range", func() error { _, err := rolled.Roll([]int{1, 2, 3, 4, 7}); return err }, InvalidDice},
+		{"roll without held dice", func() error { _, err := held.Roll([]int{1, 3, 3, 4, 5}); return err }, InvalidDice},
+		{"hold dice not rolled", func() error { _, err := rolled.Hold([]int{1, 1}); return err }, InvalidDice},
+		{"hold too many dice", func() error { _, err := rolled.Hold([]int{1, 2, 3, 4, 5, 5}); return err }, InvalidDice},
+		{"invalid box", func() error { _, _, err := rolled.Fill(Yahtzee + 1); return err }, InvalidBox},
+		{"filled box", func() error { _, _, err := filled.Fill(Chance); return err }, FilledBox},
+	}
+
+	for _, tc := range testCases {
+		err := tc.move()
+		if moveErr, ok := err.(*MoveError); !ok {
+			t.Errorf("%v: error = %v, expected a MoveError", tc.name, err)
+		} else if moveErr.Kind != tc.kind {
+			t.Errorf("%v: error kind = %v, expected %v: %v", tc.name, moveErr.Kind, tc.kind, moveErr)
+		}
+	}
+}
+
+func TestIsSubset(t *testing.T) {
+	testCases := []struct {
+		sub, dice []int
+		expected  bool
+	}{
+		{nil, []int{1, 2, 3, 4, 5}, true},
+		{nil, nil, true},
+		{[]int{1}, nil, false},
+		{[]int{3, 1}, []int{1, 2, 3, 4, 5}, true},
+		{[]int{1, 2, 3, 4, 5}, []int{5, 4, 3, 2, 1}, true},
+		{[]int{6}, []int{1, 2, 3, 4, 5}, false},
+		{[]int{2, 2}, []int{1, 2, 3, 4, 5}, false},
+		{[]int{2, 2}, []int{2, 1, 2, 4, 5}, true},
+		{[]int{2, 2, 2}, []int{2, 1, 2, 4, 5}, false},
+	}
+
+	for _, tc := range testCases {
+		if result := IsSubset(tc.sub, tc.dice); result != tc.expected {
+			t.Errorf("IsSubset(%v, %v) = %v, expected %v", tc.sub, tc.dice, result, tc.expected)
+		}
+	}
+}